@@ -2,10 +2,13 @@
 package main
 
 import (
+	"context"
 	"flag"
 	goflag "flag"
 	"fmt"
+	"io"
 	"os"
+	"strings"
 	"text/template"
 	"time"
 
@@ -15,36 +18,56 @@ import (
 	"github.com/tstromberg/campwiz/pkg/campwiz"
 	"github.com/tstromberg/campwiz/pkg/mangle"
 	"github.com/tstromberg/campwiz/pkg/metadata"
+	"github.com/tstromberg/campwiz/pkg/render"
 	"github.com/tstromberg/campwiz/pkg/search"
 	"k8s.io/klog/v2"
 )
 
 var (
-	datesFlag       *[]string      = pflag.StringSlice("dates", []string{"2021-03-05"}, "dates to search for")
-	milesFlag       *int           = pflag.Int("max_distance", 200, "distance to search within")
-	nightsFlag      *int           = pflag.Int("nights", 2, "number of nights to stay")
-	minRatingFlag   *float64       = pflag.Float64("min_rating", 0, "minimum scenery rating for inclusion")
-	keywordsFlag    *[]string      = pflag.StringSlice("keywords", nil, "keywords to search for")
-	maxCacheAgeFlag *time.Duration = pflag.Duration("max_cache_age", cache.RecommendedMaxAge, "max age of cache")
-	latFlag         *float64       = pflag.Float64("lat", 37.4092297, "latitude to search from")
-	lonFlag         *float64       = pflag.Float64("lon", -122.07237049999999, "longitude to search from")
-	providersFlag   *[]string      = pflag.StringSlice("providers", search.DefaultProviders, "site providers to include")
+	datesFlag          *[]string      = pflag.StringSlice("dates", []string{"2021-03-05"}, "dates to search for")
+	fromFlag           *string        = pflag.String("from", "", "first arrival date within a window to search (overrides default --dates)")
+	untilFlag          *string        = pflag.String("until", "", "last arrival date within a window to search")
+	weekdaysFlag       *[]string      = pflag.StringSlice("weekdays", nil, "days of the week to arrive on within the window, such as fri,sat")
+	milesFlag          *int           = pflag.Int("max_distance", 200, "distance to search within")
+	nightsFlag         *int           = pflag.Int("nights", 2, "number of nights to stay")
+	minRatingFlag      *float64       = pflag.Float64("min_rating", 0, "minimum scenery rating for inclusion")
+	keywordsFlag       *[]string      = pflag.StringSlice("keywords", nil, "keywords to search for")
+	maxCacheAgeFlag    *time.Duration = pflag.Duration("max_cache_age", cache.RecommendedMaxAge, "max age of cache")
+	persistBackendFlag *string        = pflag.String("persist_backend", "disk", "cache persistence backend (disk, sqlite), or record/replay HTTP fixtures to --persist_path")
+	persistPathFlag    *string        = pflag.String("persist_path", "", "where to persist cache to (automatic)")
+	latFlag            *float64       = pflag.Float64("lat", 37.4092297, "latitude to search from")
+	lonFlag            *float64       = pflag.Float64("lon", -122.07237049999999, "longitude to search from")
+	providersFlag      *[]string      = pflag.StringSlice("providers", search.DefaultProviders, "site providers to include")
+	outputFlag         *string        = pflag.String("output", "ansi", "output format (ansi, text, json, csv, markdown, ical)")
+	siteKindsFlag      *[]string      = pflag.StringSlice("site_kinds", nil, fmt.Sprintf("site kinds to include (%s)", strings.Join(campwiz.SiteKindList(), ", ")))
+	metadataFlag       *[]string      = pflag.StringSlice("metadata", nil, "metadata files or directories to load in order, later ones overriding earlier (default: bundled)")
+	featuresFlag       *[]string      = pflag.StringSlice("features", nil, fmt.Sprintf("features to require (%s)", strings.Join(campwiz.FeatureList(), ", ")))
+	maxPriceFlag       *float64       = pflag.Float64("max_price", 0, "maximum price per night, in dollars (campgrounds with unknown prices are kept)")
+	sortByFlag         *string        = pflag.String("sort_by", campwiz.SortRating, "order of results (rating, price)")
+	partySizeFlag      *int           = pflag.Int("party_size", 0, "number of people camping")
+	equipmentFlag      *string        = pflag.String("equipment", "", "what you will camp in (tent, rv, trailer)")
+	vehicleLengthFlag  *int           = pflag.Int("vehicle_length", 0, "length of the RV or trailer, in feet")
 
 	outTmpl = `
-{{ $srcs := .Sources }}
+{{- range .Weekends }}
+{{ printf "Weekend of %s %d" .Friday.Month .Friday.Day | hwhite }}
 {{ range $i, $r := .Results}}
 {{ Color "(" "yellow+d" }}{{ printf "#%d" $i | yellow }}{{ Color ")" "yellow+d" }} {{ Color $r.Name "green+h" }} {{ Color "(" "black+h" }}{{ printf "%.0fmi" $r.Distance | green }}{{ with $r.Locale }}{{ Color "," "black+h"}} {{ . | green }}{{ end }}{{ Color ")" "black+h" }}
 {{- range $r.Availability}}
-{{ Color "  >" "cyan" }} {{ printf "%s %d"  .Date.Month .Date.Day | hwhite }}{{ Color ":" "cyan" }} {{.SpotCount}}x{{.Kind}} - {{.URL | cyan }}
+{{ Color "  >" "cyan" }} {{ printf "%s %d"  .Date.Month .Date.Day | hwhite }}{{ Color ":" "cyan" }} {{.SpotCount}}x{{.Kind}}{{ if .PricePerNight }} {{ printf "$%.0f/night" .PricePerNight | yellow }}{{ with .TotalPrice }} {{ printf "($%.0f total)" . | yellow }}{{ end }}{{ end }} - {{.URL | cyan }}
+{{- with .Sites }}
+{{ Color "    sites:" "black+h" }} {{ range $i, $s := . }}{{ if $i }}{{ Color "," "black+h" }} {{ end }}{{ $s }}{{ end }}
 {{- end }}
-{{ with $r.KnownCampground }}
-{{- range $k, $v := .Refs -}}
- {{- $src := index $srcs $k -}}
- {{ Color "  *" "magenta" }} {{ $src.Name | hmagenta }}: {{ printf "%.0f" $v.Rating | hwhite }}{{ Color "/" "black+h" }}{{ printf "%0.0f" $src.RatingMax | hwhite }}{{ with $src.RatingDesc }} {{$src.RatingDesc }}{{ end }}{{ with $v.Lists }}{{ Color ", " "black+h" }}{{ range . }} {{ printf "#%d" .Place | hmagenta }} {{ .Title | hwhite }}{{ end }}{{ end }}
 {{- end }}
+{{- with $r.Ratings }}
+{{- range . }}
+{{ Color "  *" "magenta" }} {{ .Name | hmagenta }}: {{ printf "%.0f" .Rating | hwhite }}{{ Color "/" "black+h" }}{{ printf "%0.0f" .RatingMax | hwhite }}{{ with .Desc }} {{ . }}{{ end }}{{ with .Lists }}{{ Color ", " "black+h" }}{{ range . }} {{ printf "#%d" .Place | hmagenta }} {{ .Title | hwhite }}{{ end }}{{ end }}
+{{- end }}
+{{ Color "  =" "magenta" }} {{ printf "%.1f" $r.Rating | hwhite }}{{ Color "/" "black+h" }}{{ printf "%0.0f" $.RatingScale | hwhite }}
 {{ end }}
   {{ with $r.Desc | Ellipsis }}{{ . }}{{ end }}
 {{ end }}
+{{- end }}
 
 {{- range .Errors}}{{ Color "ERROR: " "red" }}{{ printf "%s" . | yellow }}{{ end -}}
 `
@@ -52,19 +75,21 @@ var (
 
 const dateFormat = "2006-01-02"
 
+// outputFormats are the supported values for --output
+var outputFormats = map[string]bool{"ansi": true, "text": true, "json": true, "csv": true, "markdown": true, "ical": true}
+
 type templateContext struct {
-	Query   campwiz.Query
-	Sources map[string]campwiz.Source
-	Results []campwiz.Result
-	Errors  []error
+	Query       campwiz.Query
+	RatingScale float64
+	Sources     map[string]campwiz.Source
+	Results     []campwiz.Result
+	Weekends    []campwiz.Weekend
+	Errors      []error
 }
 
-func processFlags() error {
-	cs, err := cache.New(cache.Config{MaxAge: *maxCacheAgeFlag})
-	if err != nil {
-		return err
-	}
-
+// queryFromFlags builds a query from the command-line flags
+func queryFromFlags() (campwiz.Query, error) {
+	var err error
 	q := campwiz.Query{
 		Lon:         *lonFlag,
 		Lat:         *latFlag,
@@ -72,50 +97,166 @@ func processFlags() error {
 		MaxDistance: *milesFlag,
 		MinRating:   *minRatingFlag,
 		Keywords:    *keywordsFlag,
+		MaxPrice:    *maxPriceFlag,
+
+		PartySize:     *partySizeFlag,
+		VehicleLength: *vehicleLengthFlag,
 	}
 
-	for _, ds := range *datesFlag {
-		t, err := time.Parse(dateFormat, ds)
+	q.Equipment, err = campwiz.ParseEquipment(*equipmentFlag)
+	if err != nil {
+		return q, fmt.Errorf("equipment: %w", err)
+	}
+
+	q.SortBy, err = campwiz.ParseSortBy(*sortByFlag)
+	if err != nil {
+		return q, fmt.Errorf("sort by: %w", err)
+	}
+
+	q.SiteKinds, err = campwiz.ParseSiteKinds(*siteKindsFlag)
+	if err != nil {
+		return q, fmt.Errorf("site kinds: %w", err)
+	}
+
+	q.Features, err = campwiz.ParseFeatures(*featuresFlag)
+	if err != nil {
+		return q, fmt.Errorf("features: %w", err)
+	}
+
+	if *fromFlag != "" {
+		q.From, err = time.Parse(dateFormat, *fromFlag)
+		if err != nil {
+			return q, fmt.Errorf("unable to parse from date %q: %w", *fromFlag, err)
+		}
+	}
+
+	if *untilFlag != "" {
+		q.Until, err = time.Parse(dateFormat, *untilFlag)
 		if err != nil {
-			klog.Fatalf("unable to parse date %q: %v", ds, err)
+			return q, fmt.Errorf("unable to parse until date %q: %w", *untilFlag, err)
 		}
-		q.Dates = append(q.Dates, t)
 	}
 
-	srcs, props, err := metadata.LoadAll()
+	q.Weekdays, err = campwiz.ParseWeekdays(*weekdaysFlag)
+	if err != nil {
+		return q, fmt.Errorf("weekdays: %w", err)
+	}
+
+	// The default date is only a placeholder, so don't search it alongside a window
+	if q.From.IsZero() || pflag.CommandLine.Changed("dates") {
+		for _, ds := range *datesFlag {
+			t, err := time.Parse(dateFormat, ds)
+			if err != nil {
+				return q, fmt.Errorf("unable to parse date %q: %w", ds, err)
+			}
+			q.Dates = append(q.Dates, t)
+		}
+	}
+	return q, nil
+}
+
+func processFlags() error {
+	cc := cache.Config{MaxAge: *maxCacheAgeFlag, Backend: *persistBackendFlag, Path: *persistPathFlag}
+	// Watched searches must not be answered from a cache older than the watch interval
+	if pflag.Arg(0) == "watch" {
+		cc.MaxAgeLimit = *watchIntervalFlag
+	}
+
+	cs, err := cache.New(cc)
+	if err != nil {
+		return err
+	}
+
+	q, err := queryFromFlags()
+	if err != nil {
+		return err
+	}
+
+	if !outputFormats[*outputFlag] {
+		return fmt.Errorf("unknown output format %q", *outputFlag)
+	}
+
+	srcs, props, err := metadata.Load(*metadataFlag)
 	if err != nil {
 		return fmt.Errorf("loadall failed: %w", err)
 	}
+	idx := search.NewIndex(srcs, props)
 
-	ms, errs := search.Run(*providersFlag, q, cs, props)
+	if pflag.Arg(0) == "watch" {
+		return runWatch(q, cs, idx)
+	}
 
-	fmap := template.FuncMap{
-		"Ellipsis": ellipse,
-		"Color":    ansi.Color,
-		"yellow":   func(s string) string { return ansi.Color(s, "yellow") },
-		"green":    func(s string) string { return ansi.Color(s, "green") },
-		"cyan":     func(s string) string { return ansi.Color(s, "cyan") },
-		"blue":     func(s string) string { return ansi.Color(s, "blue") },
-		"magenta":  func(s string) string { return ansi.Color(s, "magenta") },
-		"hyellow":  func(s string) string { return ansi.Color(s, "yellow+h") },
-		"hgreen":   func(s string) string { return ansi.Color(s, "green+h") },
-		"hblue":    func(s string) string { return ansi.Color(s, "blue+h") },
-		"hmagenta": func(s string) string { return ansi.Color(s, "magenta+h") },
-		"hwhite":   func(s string) string { return ansi.Color(s, "white+h") },
-		"grey":     func(s string) string { return ansi.Color(s, "black+h") },
+	if pflag.Arg(0) == "propose_ids" {
+		return runProposeIDs(os.Stdout, q, cs, idx)
 	}
 
-	t := template.Must(template.New("ascii").Funcs(fmap).Parse(outTmpl))
+	if pflag.Arg(0) == "unmatched" {
+		return runUnmatched(os.Stdout, q, cs, idx)
+	}
+
+	ms, errs := search.Run(context.Background(), *providersFlag, q, cs, idx)
 
 	c := templateContext{
-		Query:   q,
-		Results: ms,
-		Sources: srcs,
-		Errors:  errs,
+		Query:       q,
+		RatingScale: campwiz.RatingScale,
+		Results:     ms,
+		Weekends:    campwiz.GroupByWeekend(ms),
+		Sources:     srcs,
+		Errors:      errs,
+	}
+
+	if err := output(os.Stdout, *outputFlag, c); err != nil {
+		return fmt.Errorf("output: %w", err)
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%d provider(s) failed", len(errs))
+	}
+	return nil
+}
+
+// output renders results in the requested format
+func output(w io.Writer, format string, c templateContext) error {
+	rc := render.Context{Query: c.Query, Sources: c.Sources, Results: c.Results, Errors: c.Errors}
+
+	switch format {
+	case "ansi":
+		return text(w, c, ansi.Color)
+	case "text":
+		return text(w, c, func(s string, _ string) string { return s })
+	case "json":
+		return render.JSON(w, rc)
+	case "csv":
+		return render.CSV(w, rc)
+	case "markdown":
+		return render.Markdown(w, rc)
+	case "ical":
+		return render.ICal(w, rc)
+	default:
+		return fmt.Errorf("unknown output format: %q", format)
+	}
+}
+
+// text renders results using outTmpl, with a function to color strings by style
+func text(w io.Writer, c templateContext, color func(s string, style string) string) error {
+	fmap := template.FuncMap{
+		"Ellipsis": ellipse,
+		"Color":    color,
+		"yellow":   func(s string) string { return color(s, "yellow") },
+		"green":    func(s string) string { return color(s, "green") },
+		"cyan":     func(s string) string { return color(s, "cyan") },
+		"blue":     func(s string) string { return color(s, "blue") },
+		"magenta":  func(s string) string { return color(s, "magenta") },
+		"hyellow":  func(s string) string { return color(s, "yellow+h") },
+		"hgreen":   func(s string) string { return color(s, "green+h") },
+		"hblue":    func(s string) string { return color(s, "blue+h") },
+		"hmagenta": func(s string) string { return color(s, "magenta+h") },
+		"hwhite":   func(s string) string { return color(s, "white+h") },
+		"grey":     func(s string) string { return color(s, "black+h") },
 	}
 
-	err = t.ExecuteTemplate(os.Stdout, "ascii", c)
-	return err
+	t := template.Must(template.New("ascii").Funcs(fmap).Parse(outTmpl))
+	return t.ExecuteTemplate(w, "ascii", c)
 }
 
 func ellipse(s string) string {
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	pflag "github.com/spf13/pflag"
+	"github.com/tstromberg/campwiz/pkg/cache"
+	"github.com/tstromberg/campwiz/pkg/campwiz"
+	"github.com/tstromberg/campwiz/pkg/search"
+	"gopkg.in/yaml.v3"
+)
+
+var minConfidenceFlag *float64 = pflag.Float64("min_confidence", 0.9, "in propose_ids mode, minimum match confidence to propose a reservation ID for")
+
+// runProposeIDs searches, and suggests reservation ID mappings for confidently matched campgrounds
+func runProposeIDs(w io.Writer, q campwiz.Query, cs cache.Store, idx *search.Index) error {
+	rs, errs := search.Run(context.Background(), *providersFlag, q, cs, idx)
+	ps := search.ProposeIDs(rs, *minConfidenceFlag)
+
+	if len(ps) > 0 {
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(ps); err != nil {
+			return fmt.Errorf("encode: %w", err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%d provider(s) failed: %v", len(errs), errs)
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/tstromberg/campwiz/pkg/cache"
+	"github.com/tstromberg/campwiz/pkg/campwiz"
+	"github.com/tstromberg/campwiz/pkg/search"
+)
+
+// runUnmatched searches, and reports results that could not be confidently tied to metadata
+func runUnmatched(w io.Writer, q campwiz.Query, cs cache.Store, idx *search.Index) error {
+	fs, errs := search.Audit(context.Background(), *providersFlag, q, cs, idx)
+	if err := writeFindings(w, fs); err != nil {
+		return err
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%d provider(s) failed: %v", len(errs), errs)
+	}
+	return nil
+}
+
+// writeFindings writes unmatched and ambiguous results as plain text
+func writeFindings(w io.Writer, fs []search.Finding) error {
+	for _, f := range fs {
+		r := f.Result
+		id := strings.TrimSpace(r.ResID)
+		if _, err := fmt.Fprintf(w, "%s: %q (%s %s, %.0fmi)\n", strings.ToUpper(f.Status), r.Name, search.ResHost(r.ResURL), id, r.Distance); err != nil {
+			return err
+		}
+
+		for _, m := range f.Matches {
+			cg := m.Campground
+			if _, err := fmt.Fprintf(w, "  %.2f %s %s (%s): %s\n", m.Confidence, cg.PropertyID, cg.ID, cg.Name, m.Detail); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"os"
+	"strings"
+	"time"
+
+	pflag "github.com/spf13/pflag"
+	"github.com/tstromberg/campwiz/pkg/cache"
+	"github.com/tstromberg/campwiz/pkg/campwiz"
+	"github.com/tstromberg/campwiz/pkg/search"
+	"github.com/tstromberg/campwiz/pkg/watch"
+)
+
+var (
+	watchIntervalFlag *time.Duration = pflag.Duration("watch_interval", 15*time.Minute, "how often to search in watch mode")
+	notifyInitialFlag *bool          = pflag.Bool("notify_initial", false, "in watch mode, notify about sites available at startup")
+	notifyWebhookFlag *string        = pflag.String("notify_webhook", "", "in watch mode, URL to POST new availability to as JSON")
+	notifySMTPFlag    *string        = pflag.String("notify_smtp", "", "in watch mode, host:port of a mail server to send new availability through")
+	notifySMTPUser    *string        = pflag.String("notify_smtp_user", "", "user to authenticate to the mail server as, with the password in $CAMPWIZ_SMTP_PASSWORD")
+	notifyFromFlag    *string        = pflag.String("notify_from", "campwiz@localhost", "e-mail address to send notifications from")
+	notifyToFlag      *[]string      = pflag.StringSlice("notify_to", nil, "e-mail addresses to send notifications to")
+)
+
+// notifiers returns the notifiers configured by flags
+func notifiers() ([]watch.Notifier, error) {
+	ns := []watch.Notifier{&watch.TextNotifier{W: os.Stdout}}
+
+	if *notifyWebhookFlag != "" {
+		ns = append(ns, &watch.WebhookNotifier{URL: *notifyWebhookFlag})
+	}
+
+	if *notifySMTPFlag != "" {
+		if len(*notifyToFlag) == 0 {
+			return nil, fmt.Errorf("--notify_to is required with --notify_smtp")
+		}
+
+		sn := &watch.SMTPNotifier{Addr: *notifySMTPFlag, From: *notifyFromFlag, To: *notifyToFlag}
+		if *notifySMTPUser != "" {
+			host, _, err := net.SplitHostPort(*notifySMTPFlag)
+			if err != nil {
+				return nil, fmt.Errorf("smtp address: %w", err)
+			}
+			sn.Auth = smtp.PlainAuth("", *notifySMTPUser, os.Getenv("CAMPWIZ_SMTP_PASSWORD"), host)
+		}
+		ns = append(ns, sn)
+	}
+
+	return ns, nil
+}
+
+// runWatch searches repeatedly, notifying about newly available sites
+func runWatch(q campwiz.Query, cs cache.Store, idx *search.Index) error {
+	ns, err := notifiers()
+	if err != nil {
+		return err
+	}
+
+	name := strings.Join(pflag.Args()[1:], " ")
+	if name == "" {
+		name = "your search"
+	}
+
+	w := watch.New(watch.Config{
+		Queries:       []watch.Saved{{Name: name, Query: q}},
+		Providers:     *providersFlag,
+		Cache:         cs,
+		Index:         idx,
+		Notifiers:     ns,
+		Interval:      *watchIntervalFlag,
+		NotifyInitial: *notifyInitialFlag,
+	})
+	return w.Run(context.Background())
+}
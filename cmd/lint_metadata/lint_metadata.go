@@ -0,0 +1,36 @@
+// lint_metadata checks metadata files for problems, defaulting to those in the source tree
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/tstromberg/campwiz/pkg/metadata"
+	"github.com/tstromberg/campwiz/pkg/relpath"
+	"k8s.io/klog/v2"
+)
+
+func main() {
+	klog.InitFlags(nil)
+	flag.Parse()
+
+	paths := flag.Args()
+	if len(paths) == 0 {
+		paths = []string{relpath.Find(metadata.DefaultDir)}
+	}
+
+	ps, err := metadata.Lint(paths)
+	if err != nil {
+		klog.Exitf("lint failed: %v", err)
+	}
+
+	for _, p := range ps {
+		fmt.Println(p)
+	}
+
+	if len(ps) > 0 {
+		fmt.Fprintf(os.Stderr, "%d problem(s) found\n", len(ps))
+		os.Exit(1)
+	}
+}
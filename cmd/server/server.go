@@ -2,10 +2,12 @@
 package main
 
 import (
+	"context"
 	goflag "flag"
 	"fmt"
 	"net/http"
 	"os"
+	"time"
 
 	pflag "github.com/spf13/pflag"
 	"k8s.io/klog/v2"
@@ -18,12 +20,14 @@ import (
 )
 
 var (
-	persistBackendFlag           = pflag.String("persist-backend", "", "Cache persistence backend (disk, mysql, cloudsql)")
+	persistBackendFlag           = pflag.String("persist-backend", "", "Cache persistence backend (disk, sqlite)")
 	persistPathFlag              = pflag.String("persist-path", "", "Where to persist cache to (automatic)")
 	portFlag                     = pflag.Int("port", 8080, "port to run server at")
 	siteFlag                     = pflag.String("site", "site/", "path to site files")
 	thirdPartyFlag               = pflag.String("3p", "third_party/", "path to 3rd party files")
 	providersFlag      *[]string = pflag.StringSlice("providers", search.DefaultProviders, "site providers to include")
+	metadataFlag       *[]string = pflag.StringSlice("metadata", nil, "metadata files or directories to load in order, later ones overriding earlier (default: bundled)")
+	metadataPollFlag             = pflag.Duration("metadata-poll", time.Minute, "how often to check metadata files for changes to reload (0 to disable)")
 
 	latFlag *float64 = pflag.Float64("lat", 37.4092297, "latitude to search from")
 	lonFlag *float64 = pflag.Float64("lon", -122.07237049999999, "longitude to search from")
@@ -34,21 +38,34 @@ func main() {
 	pflag.CommandLine.AddGoFlagSet(goflag.CommandLine)
 	pflag.Parse()
 
-	cs, err := cache.New(cache.Config{MaxAge: cache.RecommendedMaxAge})
+	cs, err := cache.New(cache.Config{
+		MaxAge:  cache.RecommendedMaxAge,
+		Backend: *persistBackendFlag,
+		Path:    *persistPathFlag,
+	})
 	if err != nil {
 		klog.Exitf("error: %w", err)
 	}
 
-	srcs, props, err := metadata.LoadAll()
+	srcs, props, err := metadata.Load(*metadataFlag)
 	if err != nil {
 		klog.Exitf("loadall failed: %v", err)
 	}
 
+	live := search.NewLive(search.NewIndex(srcs, props))
+	rl, err := search.NewReloader(*metadataFlag, live)
+	if err != nil {
+		klog.Warningf("metadata will not be reloaded: %v", err)
+	} else if *metadataPollFlag > 0 {
+		go rl.Watch(context.Background(), *metadataPollFlag)
+	}
+
 	s := site.New(&site.Config{
 		BaseDirectory: relpath.Find(*siteFlag),
 		Cache:         cs,
-		Sources:       srcs,
-		Properties:    props,
+		Index:         live,
+		Reloader:      rl,
+		AdminToken:    os.Getenv("CAMPWIZ_ADMIN_TOKEN"),
 		Providers:     *providersFlag,
 		Latitude:      *latFlag,
 		Longitude:     *lonFlag,
@@ -61,6 +78,9 @@ func main() {
 
 	http.HandleFunc("/", s.Root())
 	http.HandleFunc("/search", s.Search())
+	http.HandleFunc("/api/v1/search", s.SearchAPI())
+	http.HandleFunc("/search.ics", s.SearchICS())
+	http.HandleFunc("/admin/reload", s.Reload())
 	http.HandleFunc("/healthz", s.Healthz())
 	http.HandleFunc("/threadz", s.Threadz())
 	klog.Infof("Listening at: %s", listenAddr)
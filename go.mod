@@ -1,6 +1,6 @@
 module github.com/tstromberg/campwiz
 
-go 1.14
+go 1.16
 
 require (
 	github.com/PuerkitoBio/goquery v1.6.0
@@ -9,6 +9,7 @@ require (
 	github.com/google/go-cmp v0.5.3
 	github.com/kr/pretty v0.2.0 // indirect
 	github.com/mattn/go-colorable v0.1.8 // indirect
+	github.com/mattn/go-sqlite3 v1.14.6
 	github.com/mgutz/ansi v0.0.0-20200706080929-d51e80ef957d
 	github.com/moul/http2curl v1.0.0
 	github.com/peterbourgon/diskv v2.0.1+incompatible
@@ -0,0 +1,8 @@
+// Package metadata bundles the default metadata files, so that binaries do not depend on the source tree
+package metadata
+
+import "embed"
+
+// FS contains the bundled metadata files
+//go:embed *.yaml
+var FS embed.FS
@@ -1,8 +1,11 @@
 package backend
 
 import (
+	"context"
 	"fmt"
 	"net/http/cookiejar"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/tstromberg/campwiz/pkg/cache"
@@ -26,8 +29,8 @@ type Provider interface {
 	// Name is a human readable name for a runtime
 	Name() string
 
-	// List lists open campsites
-	List(q campwiz.Query) ([]campwiz.Result, error)
+	// List lists open campsites, giving up when ctx is done
+	List(ctx context.Context, q campwiz.Query) ([]campwiz.Result, error)
 }
 
 // Config is runtime configuration
@@ -36,6 +39,8 @@ type Config struct {
 	Type string
 	// Store is the cache implementation to use
 	Store cache.Store
+	// BaseURL overrides the root URL of the providers site, for example to test against a fake server
+	BaseURL string
 }
 
 // New returns an appropriately configured backend
@@ -47,20 +52,30 @@ func New(c Config) (Provider, error) {
 
 	switch c.Type {
 	case "ramerica":
-		return &RAmerica{store: c.Store, jar: jar}, nil
+		return &RAmerica{store: c.Store, jar: jar, base: c.BaseURL}, nil
 	case "rcalifornia":
-		return &RCalifornia{store: c.Store, jar: jar}, nil
+		return &RCalifornia{store: c.Store, jar: jar, base: c.BaseURL}, nil
 	case "rcaliforniaAdv":
-		return &RCaliforniaAdv{store: c.Store, jar: jar}, nil
+		return &RCaliforniaAdv{store: c.Store, jar: jar, base: c.BaseURL}, nil
+	case "recgov":
+		return &RecreationGov{store: c.Store, jar: jar, base: c.BaseURL}, nil
 	case "scc":
-		return &SantaClaraCounty{store: c.Store, jar: jar}, nil
+		return &SantaClaraCounty{store: c.Store, jar: jar, base: c.BaseURL}, nil
 	case "smc":
-		return &SanMateoCounty{store: c.Store, jar: jar}, nil
+		return &SanMateoCounty{store: c.Store, jar: jar, base: c.BaseURL}, nil
 	default:
 		return nil, fmt.Errorf("unknown backend type: %q", c.Type)
 	}
 }
 
+// root returns the root URL of a site: base if it was overridden, otherwise def
+func root(base string, def string) string {
+	if base != "" {
+		return strings.TrimSuffix(base, "/")
+	}
+	return def
+}
+
 // mergeDates merges multiple dates together
 func mergeDates(res []campwiz.Result) []campwiz.Result {
 	klog.V(1).Infof("Merging %d results ...", len(res))
@@ -79,10 +94,17 @@ func mergeDates(res []campwiz.Result) []campwiz.Result {
 		}
 	}
 
+	// Walk the keys in order so that results are deterministic
+	keys := []string{}
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
 	var merged []campwiz.Result
-	for k, v := range m {
-		klog.V(1).Infof("%s: %+v", k, v)
-		merged = append(merged, v)
+	for _, k := range keys {
+		klog.V(1).Infof("%s: %+v", k, m[k])
+		merged = append(merged, m[k])
 	}
 	return merged
 }
@@ -91,3 +113,38 @@ func mergeDates(res []campwiz.Result) []campwiz.Result {
 func endDate(start time.Time, stayLength int) time.Time {
 	return start.Add(time.Duration(stayLength) * 24 * time.Hour)
 }
+
+// sleep pauses for the duration given, returning early if ctx is done
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// statusError returns an error if a response has a non-2xx status code
+func statusError(r cache.Response) error {
+	if r.StatusCode < 200 || r.StatusCode > 299 {
+		return fmt.Errorf("unexpected status code %d from %s", r.StatusCode, r.URL)
+	}
+	return nil
+}
+
+// invalidate removes responses from the cache, so that a failed search is refetched next time
+func invalidate(cs cache.Store, rs ...cache.Response) {
+	for _, r := range rs {
+		if r.Key == "" {
+			continue
+		}
+
+		klog.Warningf("invalidating cached response from %s (%s)", r.URL, r.Key)
+		if err := cs.Delete(r.Key); err != nil {
+			klog.Errorf("unable to delete %s: %v", r.Key, err)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package backend
+
+import (
+	"bytes"
+	"encoding/gob"
+	"flag"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/tstromberg/campwiz/pkg/cache"
+)
+
+// recordFlag re-records fixtures from the live reservation sites, for example: go test ./pkg/backend -run List -record
+var recordFlag = flag.Bool("record", false, "record fixtures from live sites rather than replaying them")
+
+// fixture returns a store which replays the HTTP exchanges in testdata/name, or records them with --record
+func fixture(t *testing.T, name string) *cache.Fixture {
+	t.Helper()
+
+	mode := cache.Replay
+	if *recordFlag {
+		mode = cache.Record
+	}
+
+	f, err := cache.NewFixture(filepath.Join("testdata", name), mode)
+	if err != nil {
+		t.Fatalf("fixture: %v", err)
+	}
+
+	// No delay is needed between replayed requests
+	if mode == cache.Replay {
+		old := uncachedDelay
+		uncachedDelay = 0
+		t.Cleanup(func() { uncachedDelay = old })
+	}
+
+	t.Cleanup(func() {
+		if u := f.Unused(); len(u) > 0 {
+			t.Errorf("%d recorded exchanges were not requested, first: %s %s", len(u), u[0].Method, u[0].URL)
+		}
+	})
+	return f
+}
+
+// fakeStore is an in-memory cache.Store
+type fakeStore struct {
+	seen map[string][]byte
+}
+
+func (f *fakeStore) Read(key string) ([]byte, error) {
+	bs, exists := f.seen[key]
+	if !exists {
+		return bs, fmt.Errorf("%q not found", key)
+	}
+	return bs, nil
+}
+
+func (f *fakeStore) Write(key string, bs []byte) error {
+	f.seen[key] = bs
+	return nil
+}
+
+func (f *fakeStore) Delete(key string) error {
+	delete(f.seen, key)
+	return nil
+}
+
+// seed populates the store with a fresh response for a request, so that it may be fetched offline
+func seed(t *testing.T, cs *fakeStore, req cache.Request, body []byte) {
+	t.Helper()
+
+	// Match the key that cache.Fetch will look up
+	if req.Method == "" {
+		req.Method = "GET"
+	}
+
+	var buf bytes.Buffer
+	r := cache.Response{URL: req.URL, StatusCode: 200, Body: body, MTime: time.Now()}
+	if err := gob.NewEncoder(&buf).Encode(&r); err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	cs.seen[req.Key()] = buf.Bytes()
+}
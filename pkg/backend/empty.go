@@ -1,6 +1,7 @@
 package backend
 
 import (
+	"context"
 	"fmt"
 	"net/http/cookiejar"
 	"net/url"
@@ -24,16 +25,24 @@ func (b *Empty) Name() string {
 }
 
 // List lists available sites
-func (b *Empty) List(q campwiz.Query) ([]campwiz.Result, error) {
+func (b *Empty) List(ctx context.Context, q campwiz.Query) ([]campwiz.Result, error) {
 	klog.Infof("Empty.List: %+v", q)
-	_, err := cache.Fetch(b.startPage(), b.store)
+	start, err := cache.FetchContext(ctx, b.startPage(), b.store)
 	if err != nil {
 		return nil, fmt.Errorf("fetch start: %w", err)
 	}
+	if err := statusError(start); err != nil {
+		invalidate(b.store, start)
+		return nil, fmt.Errorf("start: %w", err)
+	}
 
 	var res []campwiz.Result
 	for _, d := range q.Dates {
-		rs, err := b.avail(q, d)
+		if err := ctx.Err(); err != nil {
+			return res, err
+		}
+
+		rs, err := b.avail(ctx, q, d, start)
 		if err != nil {
 			return res, fmt.Errorf("avail: %w", err)
 		}
@@ -86,15 +95,20 @@ func (b *Empty) parse(bs []byte, date time.Time, q campwiz.Query) ([]campwiz.Res
 }
 
 // avail lists sites available on a single date
-func (b *Empty) avail(q campwiz.Query, d time.Time) ([]campwiz.Result, error) {
+func (b *Empty) avail(ctx context.Context, q campwiz.Query, d time.Time, start cache.Response) ([]campwiz.Result, error) {
 	req := b.req(q, d)
-	resp, err := cache.Fetch(req, b.store)
+	resp, err := cache.FetchContext(ctx, req, b.store)
 	if err != nil {
 		return nil, fmt.Errorf("fetch: %w", err)
 	}
+	if err := statusError(resp); err != nil {
+		invalidate(b.store, resp, start)
+		return nil, err
+	}
 
 	prs, err := b.parse(resp.Body, d, q)
 	if err != nil {
+		invalidate(b.store, resp, start)
 		return nil, fmt.Errorf("parse: %w", err)
 	}
 
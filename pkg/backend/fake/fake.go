@@ -0,0 +1,399 @@
+// Package fake is a local reservation server, emulating the sites queried by backends so that they may be tested end to end.
+package fake
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+const (
+	// sessionCookie is the name of the cookie handed out by start pages
+	sessionCookie = "FAKESESSION"
+	// defaultPageSize is how many ReserveAmerica records are returned per page
+	defaultPageSize = 2
+)
+
+// Campground is a campground which the fake server lists
+type Campground struct {
+	// ID is the providers ID: NamingID for ReserveAmerica, PlaceID for ReserveCalifornia, or the San Mateo site ID
+	ID    string
+	Name  string
+	Miles float64
+	Lat   float64
+	Lon   float64
+	// Sites are individual site numbers, listed by Santa Clara County
+	Sites []string
+	// Kind is the type of site, such as "Camping - Tent". San Mateo County sites of an "Electric" kind have hookups.
+	Kind string
+	// Dates are the arrival dates (YYYY-MM-DD) which are available. If empty, every date is available.
+	Dates []string
+	// Price is the nightly fee, listed by ReserveCalifornia and San Mateo County
+	Price float64
+	// MaxRV is the longest RV allowed on San Mateo County sites, in feet
+	MaxRV int
+}
+
+// rate returns the campgrounds nightly fee as a provider would describe it
+func (c Campground) rate() string {
+	if c.Price == 0 {
+		return ""
+	}
+	return fmt.Sprintf("$%.2f", c.Price)
+}
+
+// available returns true if the campground may be reserved for an arrival date
+func (c Campground) available(date string) bool {
+	if len(c.Dates) == 0 {
+		return true
+	}
+	for _, d := range c.Dates {
+		if d == date {
+			return true
+		}
+	}
+	return false
+}
+
+// Data is what the fake server lists for each site
+type Data struct {
+	RAmerica    []Campground
+	RCalifornia []Campground
+	SCC         []Campground
+	SMC         []Campground
+
+	// PageSize is how many ReserveAmerica records to return per page
+	PageSize int
+}
+
+// Server is a fake reservation server
+type Server struct {
+	*httptest.Server
+	data Data
+
+	mu sync.Mutex
+	// failures are status codes to return for a path instead of content
+	failures map[string]int
+	// requests are the paths requested, in order
+	requests []string
+}
+
+// New starts a fake reservation server, which should be closed once done
+func New(d Data) *Server {
+	if d.PageSize == 0 {
+		d.PageSize = defaultPageSize
+	}
+
+	s := &Server{data: d, failures: map[string]int{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/explore/search-results", s.start)
+	mux.HandleFunc("/jaxrs-json/search", s.session(s.raSearch))
+	mux.HandleFunc("/rdr/rdr/search/place", s.rcSearch)
+	mux.HandleFunc("/index.asp", s.sccIndex)
+	mux.HandleFunc("/sanmateo/campsites/feed.html", s.session(s.smcFeed))
+	mux.HandleFunc("/sanmateo/", s.start)
+
+	s.Server = httptest.NewServer(s.record(mux))
+	return s
+}
+
+// Fail causes requests for path to return an error status code
+func (s *Server) Fail(path string, code int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures[path] = code
+}
+
+// Requests returns the paths that have been requested, in order
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string{}, s.requests...)
+}
+
+// record logs requests and injects failures
+func (s *Server) record(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		klog.V(1).Infof("fake %s %s", r.Method, r.URL)
+
+		s.mu.Lock()
+		s.requests = append(s.requests, r.URL.Path)
+		code := s.failures[r.URL.Path]
+		s.mu.Unlock()
+
+		if code != 0 {
+			http.Error(w, http.StatusText(code), code)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// start serves a start page, handing out a session cookie
+func (s *Server) start(w http.ResponseWriter, r *http.Request) {
+	http.SetCookie(w, &http.Cookie{Name: sessionCookie, Value: strconv.FormatInt(time.Now().UnixNano(), 36), Path: "/"})
+	fmt.Fprintf(w, "<html><body>%s</body></html>", r.URL.Path)
+}
+
+// session requires a session cookie and a referrer, as the real sites do
+func (s *Server) session(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if _, err := r.Cookie(sessionCookie); err != nil {
+			http.Error(w, "session expired", http.StatusForbidden)
+			return
+		}
+		if r.Header.Get("Referrer") == "" {
+			http.Error(w, "missing referrer", http.StatusBadRequest)
+			return
+		}
+		h(w, r)
+	}
+}
+
+// date converts a date in layout to YYYY-MM-DD
+func date(layout string, s string) (string, error) {
+	t, err := time.Parse(layout, s)
+	if err != nil {
+		return "", err
+	}
+	return t.Format("2006-01-02"), nil
+}
+
+// raSearch emulates the paginated ReserveAmerica JSON search
+func (s *Server) raSearch(w http.ResponseWriter, r *http.Request) {
+	arrival := r.FormValue("arv")
+	page, err := strconv.Atoi(r.FormValue("rcp"))
+	if err != nil || arrival == "" {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+
+	type availability struct {
+		Available bool `json:"available"`
+	}
+	type details struct {
+		BaseURL      string       `json:"baseURL"`
+		ImageURL     string       `json:"imageURL"`
+		Availability availability `json:"availability"`
+	}
+	type record struct {
+		NamingID  string  `json:"namingId"`
+		Name      string  `json:"name"`
+		Proximity float64 `json:"proximity"`
+		Details   details `json:"details"`
+	}
+
+	cgs := s.data.RAmerica
+	total := (len(cgs) + s.data.PageSize - 1) / s.data.PageSize
+	start := page * s.data.PageSize
+	end := start + s.data.PageSize
+	if start > len(cgs) {
+		start = len(cgs)
+	}
+	if end > len(cgs) {
+		end = len(cgs)
+	}
+
+	records := []record{}
+	for _, c := range cgs[start:end] {
+		records = append(records, record{
+			NamingID:  c.ID,
+			Name:      c.Name,
+			Proximity: c.Miles,
+			Details: details{
+				BaseURL:      "/camping/r/facilityDetails.do?parkId=" + c.ID,
+				ImageURL:     "/webphotos/" + c.ID + ".jpg",
+				Availability: availability{Available: c.available(arrival)},
+			},
+		})
+	}
+
+	writeJSON(w, map[string]interface{}{
+		"totalRecords": len(cgs),
+		"totalPages":   total,
+		"startIndex":   start,
+		"endIndex":     end,
+		"control":      map[string]int{"currentPage": page, "pageSize": s.data.PageSize},
+		"records":      records,
+	})
+}
+
+// rcSearch emulates the ReserveCalifornia place search, which takes a JSON body
+func (s *Server) rcSearch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+		http.Error(w, "expected a JSON POST", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		StartDate   string
+		NearbyLimit int
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	arrival, err := date("01-02-2006", req.StartDate)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	type place struct {
+		Available         bool    `json:"Available"`
+		Latitude          float64 `json:"Latitude"`
+		Longitude         float64 `json:"Longitude"`
+		MilesFromSelected int     `json:"MilesFromSelected"`
+		Name              string  `json:"Name"`
+		PlaceID           int     `json:"PlaceId"`
+
+		Facilities map[string]map[string]string `json:"Facilities"`
+	}
+
+	places := []place{}
+	for _, c := range s.data.RCalifornia {
+		if req.NearbyLimit > 0 && c.Miles > float64(req.NearbyLimit) {
+			continue
+		}
+		id, err := strconv.Atoi(c.ID)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("place id %q: %v", c.ID, err), http.StatusInternalServerError)
+			return
+		}
+		places = append(places, place{
+			Available:         c.available(arrival),
+			Latitude:          c.Lat,
+			Longitude:         c.Lon,
+			MilesFromSelected: int(c.Miles),
+			Name:              c.Name,
+			PlaceID:           id,
+			Facilities:        map[string]map[string]string{c.ID: {"Name": c.Name, "RateMessage": c.rate()}},
+		})
+	}
+
+	writeJSON(w, map[string]interface{}{"NearbyPlaces": places})
+}
+
+// sccTmpl is the portion of the Santa Clara County search results that is parsed
+var sccTmpl = template.Must(template.New("scc").Parse(`<html><body>
+<div id="list_camping"><table>
+{{ range . }}<tr class="TableItem">
+<td><span class="heavy_blue">  {{ .Site }}</span><br>
+<span class="body_blue">{{ .Kind }}</span><br>
+<span class="body_gray">{{ .Name }}</span></td>
+<td class="FilterElement"><a href="/reservations/SiteDetails.asp?SiteID={{ .Site }}">reserve</a></td>
+</tr>
+{{ end }}</table></div>
+</body></html>`))
+
+// sccIndex emulates Santa Clara County index.asp, which is both the start page and the search page
+func (s *Server) sccIndex(w http.ResponseWriter, r *http.Request) {
+	if r.FormValue("actiontype") == "" {
+		s.start(w, r)
+		return
+	}
+
+	s.session(func(w http.ResponseWriter, r *http.Request) {
+		arrival, err := date("01/02/2006", r.FormValue("arrive_date"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		type row struct {
+			Site string
+			Kind string
+			Name string
+		}
+		rows := []row{}
+		for _, c := range s.data.SCC {
+			if !c.available(arrival) {
+				continue
+			}
+			for _, site := range c.Sites {
+				rows = append(rows, row{Site: site, Kind: c.Kind, Name: c.Name})
+			}
+		}
+
+		if err := sccTmpl.Execute(w, rows); err != nil {
+			klog.Errorf("scc template: %v", err)
+		}
+	})(w, r)
+}
+
+// smcFeed emulates the San Mateo County XML availability feed
+func (s *Server) smcFeed(w http.ResponseWriter, r *http.Request) {
+	if r.FormValue("code") == "" || r.FormValue("endDate") == "" {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	arrival := r.FormValue("startDate")
+
+	type site struct {
+		SiteID     string `xml:"siteId,attr"`
+		Available  int    `xml:"avail,attr"`
+		Price      string `xml:"price,attr"`
+		MaxRV      string `xml:"maxRV,attr,omitempty"`
+		Electrical string `xml:"electrical,attr,omitempty"`
+	}
+	type sites struct {
+		XMLName xml.Name `xml:"sites"`
+		Sites   []site   `xml:"site"`
+	}
+
+	// The feed only covers the park the session was started for
+	park := lastSegment(r.Header.Get("Referrer"))
+
+	var out sites
+	for _, c := range s.data.SMC {
+		if c.ID != park {
+			continue
+		}
+		for _, id := range c.Sites {
+			avail := 0
+			if c.available(arrival) {
+				avail = 1
+			}
+			st := site{SiteID: id, Available: avail, Price: c.rate()}
+			if c.MaxRV > 0 {
+				st.MaxRV = strconv.Itoa(c.MaxRV)
+			}
+			if strings.Contains(c.Kind, "Electric") {
+				st.Electrical = "Yes"
+			}
+			out.Sites = append(out.Sites, st)
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/xml")
+	if err := xml.NewEncoder(w).Encode(out); err != nil {
+		klog.Errorf("smc encode: %v", err)
+	}
+}
+
+// lastSegment returns the last path segment of a URL
+func lastSegment(s string) string {
+	for i := len(s) - 1; i >= 0; i-- {
+		if s[i] == '/' {
+			return s[i+1:]
+		}
+	}
+	return s
+}
+
+// writeJSON writes a JSON response
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		klog.Errorf("encode: %v", err)
+	}
+}
@@ -0,0 +1,107 @@
+package backend
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tstromberg/campwiz/pkg/backend/fake"
+	"github.com/tstromberg/campwiz/pkg/campwiz"
+)
+
+// fakeData is served to backends under test
+var fakeData = fake.Data{
+	RAmerica: []fake.Campground{
+		{ID: "STAN_1040013", Name: "Frank Raines Regional Park", Miles: 62.9},
+		{ID: "PRCG_1060800", Name: "Clear Lake Campground", Miles: 81.4, Dates: []string{"2021-03-12"}},
+		{ID: "CA_120081", Name: "Lake Solano Park", Miles: 77.2},
+	},
+	RCalifornia: []fake.Campground{
+		{ID: "718", Name: "Big Basin Redwoods SP", Miles: 28, Lat: 37.17, Lon: -122.22, Price: 35},
+		{ID: "661", Name: "Pfeiffer Big Sur SP", Miles: 112, Lat: 36.25, Lon: -121.78},
+	},
+	SCC: []fake.Campground{
+		{Name: "Coyote Lake", Kind: "Camping - RV/Electric", Sites: []string{"6RV", "9RV"}},
+		{Name: "Mt. Madonna", Kind: "Camping - Tent", Sites: []string{"12"}, Dates: []string{"2021-03-12"}},
+	},
+	SMC: []fake.Campground{
+		{ID: "coyote-point", Sites: []string{"1", "2"}, Price: 45},
+		{ID: "huddart-park", Sites: []string{"7"}, Dates: []string{"2021-03-12"}},
+	},
+}
+
+func TestListFake(t *testing.T) {
+	old := uncachedDelay
+	uncachedDelay = 0
+	defer func() { uncachedDelay = old }()
+
+	date, err := time.Parse("2006-01-02", "2021-02-12")
+	if err != nil {
+		t.Fatalf("time parse: %v", err)
+	}
+	q := campwiz.Query{
+		Dates:       []time.Time{date},
+		StayLength:  2,
+		Lat:         37.2,
+		Lon:         -122.1,
+		MaxDistance: 100,
+	}
+
+	var tests = []struct {
+		backend string
+		// fail is a path to return an error from
+		fail string
+		want []string
+		// price is the lowest nightly price expected of the first result
+		price float64
+	}{
+		{backend: "ramerica", want: []string{"Lake Solano Park", "Frank Raines Regional Park"}},
+		{backend: "ramerica", fail: "/explore/search-results"},
+		{backend: "ramerica", fail: "/jaxrs-json/search"},
+		{backend: "rcalifornia", want: []string{"Big Basin Redwoods SP"}, price: 35},
+		{backend: "rcalifornia", fail: "/rdr/rdr/search/place"},
+		{backend: "scc", want: []string{"Coyote Lake"}},
+		{backend: "scc", fail: "/index.asp"},
+		{backend: "smc", want: []string{"Coyote Point"}, price: 45},
+		{backend: "smc", fail: "/sanmateo/campsites/feed.html"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.backend+tt.fail, func(t *testing.T) {
+			s := fake.New(fakeData)
+			defer s.Close()
+			if tt.fail != "" {
+				s.Fail(tt.fail, http.StatusServiceUnavailable)
+			}
+
+			p, err := New(Config{Type: tt.backend, Store: &fakeStore{seen: map[string][]byte{}}, BaseURL: s.URL})
+			if err != nil {
+				t.Fatalf("New: %v", err)
+			}
+
+			rs, err := p.List(context.Background(), q)
+			if tt.fail != "" {
+				if err == nil {
+					t.Errorf("List() with %s failing returned no error: %+v", tt.fail, rs)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("List: %v\nrequests: %v", err, s.Requests())
+			}
+
+			got := []string{}
+			for _, r := range rs {
+				got = append(got, r.Name)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("List() mismatch (-want +got):\n%s", diff)
+			}
+			if len(rs) > 0 && rs[0].LowestPrice() != tt.price {
+				t.Errorf("%s LowestPrice() = %.2f, want %.2f", rs[0].Name, rs[0].LowestPrice(), tt.price)
+			}
+		})
+	}
+}
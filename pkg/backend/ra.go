@@ -1,6 +1,7 @@
 package backend
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http/cookiejar"
@@ -17,6 +18,8 @@ import (
 type RAmerica struct {
 	store cache.Store
 	jar   *cookiejar.Jar
+	// base overrides the root URL of the site
+	base string
 }
 
 // Name is a human readable name
@@ -25,16 +28,24 @@ func (b *RAmerica) Name() string {
 }
 
 // List lists available sites
-func (b *RAmerica) List(q campwiz.Query) ([]campwiz.Result, error) {
+func (b *RAmerica) List(ctx context.Context, q campwiz.Query) ([]campwiz.Result, error) {
 	klog.Infof("RAmerica.List: %+v", q)
-	_, err := cache.Fetch(b.startPage(), b.store)
+	start, err := cache.FetchContext(ctx, b.startPage(), b.store)
 	if err != nil {
 		return nil, fmt.Errorf("fetch start: %w", err)
 	}
+	if err := statusError(start); err != nil {
+		invalidate(b.store, start)
+		return nil, fmt.Errorf("start: %w", err)
+	}
 
 	var res []campwiz.Result
 	for _, d := range q.Dates {
-		rs, err := b.avail(q, d)
+		if err := ctx.Err(); err != nil {
+			return res, err
+		}
+
+		rs, err := b.avail(ctx, q, d, start)
 		if err != nil {
 			return res, fmt.Errorf("avail: %w", err)
 		}
@@ -46,12 +57,12 @@ func (b *RAmerica) List(q campwiz.Query) ([]campwiz.Result, error) {
 
 // url is the root URL to use for requests
 func (b *RAmerica) url(s string) string {
-	return "https" + "://" + "www." + "reserve" + "america.com" + s
+	return root(b.base, "https"+"://"+"www."+"reserve"+"america.com") + s
 }
 
 // req generates a search request
 func (b *RAmerica) req(c campwiz.Query, arrival time.Time, num int) cache.Request {
-	return cache.Request{
+	r := cache.Request{
 		URL:      b.url("/jaxrs-json/search"),
 		Referrer: b.url("/"),
 		Jar:      b.jar,
@@ -62,13 +73,25 @@ func (b *RAmerica) req(c campwiz.Query, arrival time.Time, num int) cache.Reques
 			"lat":     {fmt.Sprintf("%3.3f", c.Lat)},  // Latitude
 			"arv":     {arrival.Format("2006-01-02")}, // arrival date,
 			"lsy":     {strconv.Itoa(c.StayLength)},   // length of stay
-			"pa99999": {"2003"},                       // looking for (tent). See https://developer.active.com/docs/read/Campground_Search_API
-			// "pa12": # of people
+			"pa99999": {raEquipment(c)},               // looking for. See https://developer.active.com/docs/read/Campground_Search_API
 			// "pa24": waterfront
 			"rcs":      {"100"}, // 100 results
 			"interest": {"camping"},
 		},
 	}
+
+	if c.PartySize > 0 {
+		r.Form.Set("pa12", strconv.Itoa(c.PartySize)) // # of people
+	}
+	return r
+}
+
+// raEquipment returns the site type code to look for: RV sites (which include trailers) or tents
+func raEquipment(q campwiz.Query) string {
+	if q.Vehicle() {
+		return "2001"
+	}
+	return "2003"
 }
 
 // startPage generates an initial page request
@@ -135,8 +158,14 @@ func (b *RAmerica) parse(bs []byte, date time.Time, q campwiz.Query) ([]campwiz.
 			continue
 		}
 
+		// Results only include the site type that was searched for
+		kind := campwiz.Tent
+		if q.Vehicle() {
+			kind = campwiz.RV
+		}
+
 		a := campwiz.Availability{
-			Kind: campwiz.Tent,
+			Kind: kind,
 			Date: date,
 			URL:  b.url(r.Details.BaseURL + "&arrivalDate=" + date.Format("2006-01-02") + "&lengthOfStay=" + strconv.Itoa(q.StayLength)),
 		}
@@ -162,22 +191,32 @@ func (b *RAmerica) parse(bs []byte, date time.Time, q campwiz.Query) ([]campwiz.
 }
 
 // avail lists sites available on a single date
-func (b *RAmerica) avail(q campwiz.Query, d time.Time) ([]campwiz.Result, error) {
+func (b *RAmerica) avail(ctx context.Context, q campwiz.Query, d time.Time, start cache.Response) ([]campwiz.Result, error) {
 	var results []campwiz.Result
 
 	for i := 0; i < maxPages; i++ {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+
 		req := b.req(q, d, i)
-		resp, err := cache.Fetch(req, b.store)
+		resp, err := cache.FetchContext(ctx, req, b.store)
 		if err != nil {
 			return nil, fmt.Errorf("fetch: %w", err)
 		}
+		if err := statusError(resp); err != nil {
+			invalidate(b.store, resp, start)
+			return nil, err
+		}
 
 		prs, currentPage, totalPages, err := b.parse(resp.Body, d, q)
 		if err != nil {
+			invalidate(b.store, resp, start)
 			return nil, fmt.Errorf("parse: %w, content: %s", err, resp.Body)
 		}
 
 		if currentPage != i {
+			invalidate(b.store, resp, start)
 			return nil, fmt.Errorf("got page %d, expected page %d", currentPage, i)
 		}
 
@@ -189,7 +228,9 @@ func (b *RAmerica) avail(q campwiz.Query, d time.Time) ([]campwiz.Result, error)
 
 		if !resp.Cached {
 			klog.V(1).Infof("Previous request was uncached, sleeping ...")
-			time.Sleep(uncachedDelay)
+			if err := sleep(ctx, uncachedDelay); err != nil {
+				return results, err
+			}
 		}
 	}
 
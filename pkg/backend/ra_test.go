@@ -1,7 +1,9 @@
 package backend
 
 import (
+	"context"
 	"io/ioutil"
+	"net/http/cookiejar"
 	"testing"
 	"time"
 
@@ -79,3 +81,115 @@ func TestRAmericaParse(t *testing.T) {
 		t.Errorf("parseResp() mismatch (-want +got):\n%s", diff)
 	}
 }
+
+func TestRAmericaRequest(t *testing.T) {
+	b := &RAmerica{}
+	date, err := time.Parse("2006-01-02", "2021-02-12")
+	if err != nil {
+		t.Fatalf("time parse: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		q         campwiz.Query
+		equipment string
+		people    string
+	}{
+		{"default", campwiz.Query{StayLength: 2}, "2003", ""},
+		{"party", campwiz.Query{StayLength: 2, PartySize: 8, Equipment: campwiz.EquipmentTent}, "2003", "8"},
+		{"trailer", campwiz.Query{StayLength: 2, Equipment: campwiz.EquipmentTrailer, VehicleLength: 30}, "2001", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := b.req(tt.q, date, 0).Form
+			if got := f.Get("pa99999"); got != tt.equipment {
+				t.Errorf("pa99999 = %q, want %q", got, tt.equipment)
+			}
+			if got := f.Get("pa12"); got != tt.people {
+				t.Errorf("pa12 = %q, want %q", got, tt.people)
+			}
+		})
+	}
+}
+
+func TestRAmericaInvalidate(t *testing.T) {
+	cs := &fakeStore{seen: map[string][]byte{}}
+	b := &RAmerica{store: cs}
+
+	date, err := time.Parse("2006-01-02", "2021-02-12")
+	if err != nil {
+		t.Fatalf("time parse: %v", err)
+	}
+	q := campwiz.Query{
+		Dates:       []time.Time{date},
+		StayLength:  4,
+		Lon:         -122.07237049999999,
+		Lat:         37.4092297,
+		MaxDistance: 100,
+	}
+
+	seed(t, cs, b.startPage(), []byte("<html>welcome</html>"))
+	seed(t, cs, b.req(q, date, 0), []byte(`{"Code":"SESSION_EXPIRED"}`))
+
+	if _, err := b.List(context.Background(), q); err == nil {
+		t.Fatalf("expected error from List")
+	}
+
+	// Both the search page and the session it was fetched with should be flushed
+	if len(cs.seen) > 0 {
+		t.Errorf("cache entries remain after error: %v", cs.seen)
+	}
+}
+
+func TestRAmericaList(t *testing.T) {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		t.Fatalf("jar: %v", err)
+	}
+	b := &RAmerica{store: fixture(t, "ra_list.json"), jar: jar}
+
+	date, err := time.Parse("2006-01-02", "2021-02-12")
+	if err != nil {
+		t.Fatalf("time parse: %v", err)
+	}
+	q := campwiz.Query{
+		Dates:       []time.Time{date},
+		StayLength:  2,
+		Lon:         -122.07237049999999,
+		Lat:         37.4092297,
+		MaxDistance: 100,
+	}
+
+	got, err := b.List(context.Background(), q)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+
+	want := []campwiz.Result{
+		{
+			ResURL:   "https://www.reserveamerica.com/camping/frank-raines-regional-park/r/facilityDetails.do?contractCode=STAN&parkId=1040013",
+			ImageURL: "https://www.reserveamerica.com/webphotos/STAN_1040013.jpg",
+			ResID:    "STAN_1040013",
+			Name:     "FRANK RAINES REGIONAL PARK",
+			Distance: 62.91,
+			Availability: []campwiz.Availability{
+				{Kind: campwiz.Tent, Date: date, URL: "https://www.reserveamerica.com/camping/frank-raines-regional-park/r/facilityDetails.do?contractCode=STAN&parkId=1040013&arrivalDate=2021-02-12&lengthOfStay=2"},
+			},
+		},
+		{
+			ResURL:   "https://www.reserveamerica.com/camping/lake-solano-park/r/campgroundDetails.do?contractCode=CA&parkId=120081",
+			ImageURL: "https://www.reserveamerica.com/webphotos/CA_120081.jpg",
+			ResID:    "CA_120081",
+			Name:     "Lake Solano Park",
+			Distance: 77.2,
+			Availability: []campwiz.Availability{
+				{Kind: campwiz.Tent, Date: date, URL: "https://www.reserveamerica.com/camping/lake-solano-park/r/campgroundDetails.do?contractCode=CA&parkId=120081&arrivalDate=2021-02-12&lengthOfStay=2"},
+			},
+		},
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("List() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -1,6 +1,7 @@
 package backend
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http/cookiejar"
@@ -9,6 +10,7 @@ import (
 
 	"github.com/tstromberg/campwiz/pkg/cache"
 	"github.com/tstromberg/campwiz/pkg/campwiz"
+	"github.com/tstromberg/campwiz/pkg/mangle"
 	"k8s.io/klog/v2"
 )
 
@@ -16,6 +18,8 @@ import (
 type RCalifornia struct {
 	store cache.Store
 	jar   *cookiejar.Jar
+	// base overrides the root URL of the site
+	base string
 }
 
 // Name is a human readable name
@@ -24,10 +28,14 @@ func (b *RCalifornia) Name() string {
 }
 
 // List lists available sites
-func (b *RCalifornia) List(q campwiz.Query) ([]campwiz.Result, error) {
+func (b *RCalifornia) List(ctx context.Context, q campwiz.Query) ([]campwiz.Result, error) {
 	var res []campwiz.Result
 	for _, d := range q.Dates {
-		rs, err := b.avail(q, d)
+		if err := ctx.Err(); err != nil {
+			return res, err
+		}
+
+		rs, err := b.avail(ctx, q, d)
 		if err != nil {
 			return res, fmt.Errorf("onDate: %w", err)
 		}
@@ -39,7 +47,12 @@ func (b *RCalifornia) List(q campwiz.Query) ([]campwiz.Result, error) {
 
 // url is the root URL to use for requests
 func (b *RCalifornia) url(s string) string {
-	return "https://" + "www." + "reserve" + "california.com" + s
+	return root(b.base, "https://"+"www."+"reserve"+"california.com") + s
+}
+
+// apiURL is the root URL to use for API requests, which are served from a different host than the site
+func (b *RCalifornia) apiURL(s string) string {
+	return root(b.base, "https://"+"calirdr.usedirect"+".com") + s
 }
 
 // req creates the request object for a search.
@@ -57,6 +70,7 @@ func (b *RCalifornia) req(q campwiz.Query, arrival time.Time) (cache.Request, er
 		NearbyLimit:         q.MaxDistance,
 		NearbyOnlyAvailable: true,
 		NearbyCountLimit:    100,
+		MinVehicleLength:    q.VehicleLength,
 	}
 
 	body, err := json.Marshal(&rcr)
@@ -66,7 +80,7 @@ func (b *RCalifornia) req(q campwiz.Query, arrival time.Time) (cache.Request, er
 
 	r := cache.Request{
 		Method:      "POST",
-		URL:         "https://calirdr.usedirect.com/rdr/rdr/search/place",
+		URL:         b.apiURL("/rdr/rdr/search/place"),
 		Referrer:    b.url("/"),
 		MaxAge:      searchPageExpiry,
 		ContentType: "application/json",
@@ -76,6 +90,15 @@ func (b *RCalifornia) req(q campwiz.Query, arrival time.Time) (cache.Request, er
 	return r, nil
 }
 
+// rcKind returns the kind of site listed. The place search does not describe sites, so results of RV and trailer
+// searches, which send the vehicle length, are listed as RV sites rather than being excluded as tents.
+func rcKind(q campwiz.Query) campwiz.SiteKind {
+	if q.Vehicle() {
+		return campwiz.RV
+	}
+	return campwiz.Tent
+}
+
 type rcRequest struct {
 	PlaceID             int    `json:"PlaceId"`
 	Latitude            string `json:"Latitude"`
@@ -108,6 +131,25 @@ type rcPlace struct {
 	PlaceID           int     `json:"PlaceId"`
 	ImageURL          string  `json:"ImageUrl"`
 	URL               string  `json:"Url"`
+
+	Facilities map[string]rcFacility `json:"Facilities"`
+}
+
+type rcFacility struct {
+	Name string `json:"Name"`
+	// RateMessage describes the nightly fee, such as "$35.00"
+	RateMessage string `json:"RateMessage"`
+}
+
+// price returns the lowest nightly fee across a places facilities, or 0 if unknown
+func (p rcPlace) price() float64 {
+	lowest := 0.0
+	for _, f := range p.Facilities {
+		if fee := mangle.Price(f.RateMessage); fee > 0 && (lowest == 0 || fee < lowest) {
+			lowest = fee
+		}
+	}
+	return lowest
 }
 
 type rcResponse struct {
@@ -132,10 +174,12 @@ func (b *RCalifornia) parse(bs []byte, date time.Time, q campwiz.Query) ([]campw
 		}
 
 		a := campwiz.Availability{
-			Kind: campwiz.Tent,
-			Date: date,
-			URL:  b.url("/CaliforniaWebHome/Facilities/SearchViewUnitAvailabity.aspx"),
+			Kind:          rcKind(q),
+			Date:          date,
+			URL:           b.url("/CaliforniaWebHome/Facilities/SearchViewUnitAvailabity.aspx"),
+			PricePerNight: r.price(),
 		}
+		a.TotalPrice = a.PricePerNight * float64(q.StayLength)
 
 		rr := campwiz.Result{
 			ResURL:       b.url("/"),
@@ -144,6 +188,8 @@ func (b *RCalifornia) parse(bs []byte, date time.Time, q campwiz.Query) ([]campw
 			Desc:         r.Description,
 			Features:     strings.Split(strings.TrimSuffix(r.AllHighlights, "<br>"), "<br>"),
 			Distance:     float64(r.MilesFromSelected),
+			Lat:          r.Latitude,
+			Lon:          r.Longitude,
 			Availability: []campwiz.Availability{a},
 			URL:          r.URL,
 			ImageURL:     r.ImageURL,
@@ -157,19 +203,24 @@ func (b *RCalifornia) parse(bs []byte, date time.Time, q campwiz.Query) ([]campw
 }
 
 // avail returns sites available on a single date
-func (b *RCalifornia) avail(q campwiz.Query, d time.Time) ([]campwiz.Result, error) {
+func (b *RCalifornia) avail(ctx context.Context, q campwiz.Query, d time.Time) ([]campwiz.Result, error) {
 	req, err := b.req(q, d)
 	if err != nil {
 		return nil, fmt.Errorf("request: %w", err)
 	}
 
-	resp, err := cache.Fetch(req, b.store)
+	resp, err := cache.FetchContext(ctx, req, b.store)
 	if err != nil {
 		return nil, fmt.Errorf("fetch: %w", err)
 	}
+	if err := statusError(resp); err != nil {
+		invalidate(b.store, resp)
+		return nil, err
+	}
 
 	results, err := b.parse(resp.Body, d, q)
 	if err != nil {
+		invalidate(b.store, resp)
 		return nil, fmt.Errorf("parse: %w", err)
 	}
 
@@ -1,6 +1,7 @@
 package backend
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http/cookiejar"
@@ -14,10 +15,17 @@ import (
 	"k8s.io/klog/v2"
 )
 
+var (
+	// rcaPageSize is the number of places to request per page
+	rcaPageSize = 100
+)
+
 // RCaliforniaAdv handles RCaliforniaAdv queries
 type RCaliforniaAdv struct {
 	store cache.Store
 	jar   *cookiejar.Jar
+	// base overrides the root URL of the site
+	base string
 }
 
 // Name is a human readable name
@@ -26,10 +34,14 @@ func (b *RCaliforniaAdv) Name() string {
 }
 
 // List lists available sites
-func (b *RCaliforniaAdv) List(q campwiz.Query) ([]campwiz.Result, error) {
+func (b *RCaliforniaAdv) List(ctx context.Context, q campwiz.Query) ([]campwiz.Result, error) {
 	var res []campwiz.Result
 	for _, d := range q.Dates {
-		rs, err := b.avail(q, d)
+		if err := ctx.Err(); err != nil {
+			return res, err
+		}
+
+		rs, err := b.avail(ctx, q, d)
 		if err != nil {
 			return res, fmt.Errorf("onDate: %w", err)
 		}
@@ -41,7 +53,7 @@ func (b *RCaliforniaAdv) List(q campwiz.Query) ([]campwiz.Result, error) {
 
 // url is the root URL to use for requests
 func (b *RCaliforniaAdv) url(s string) string {
-	return "https://" + "www." + "reserve" + "california.com" + s
+	return root(b.base, "https://"+"www."+"reserve"+"california.com") + s
 }
 
 type availParams struct {
@@ -74,16 +86,19 @@ type rcAdvancedRequest struct {
 	ScreenResolution            int
 }
 
-// req creates the request object for a search.
-func (b *RCaliforniaAdv) req(q campwiz.Query, arrival time.Time) (cache.Request, error) {
+// req creates the request object for a page of search results.
+func (b *RCaliforniaAdv) req(q campwiz.Query, arrival time.Time, page int) (cache.Request, error) {
 	rcr := rcAdvancedRequest{
 		GooglePlaceSearchParameters: googleParams{
 			Latitude:  fmt.Sprintf("%.4f", q.Lat),
 			Longitude: fmt.Sprintf("%.4f", q.Lon),
 			ZoomLevel: 6,
 			AvailabilitySearchParams: availParams{
-				StartDate: arrival.Format("01-02-2006"),
-				Nights:    fmt.Sprintf("%d", q.StayLength),
+				StartDate:   arrival.Format("01-02-2006"),
+				Nights:      fmt.Sprintf("%d", q.StayLength),
+				PageIndex:   page,
+				PageSize:    rcaPageSize,
+				NoOfRecords: rcaPageSize,
 			},
 		},
 		ScreenResolution: 1422,
@@ -96,8 +111,8 @@ func (b *RCaliforniaAdv) req(q campwiz.Query, arrival time.Time) (cache.Request,
 
 	r := cache.Request{
 		Method:      "POST",
-		URL:         "https://www.reservecalifornia.com/CaliforniaWebHome/Facilities/AdvanceSearch.aspx/GetPlaceData",
-		Referrer:    "https://www.reservecalifornia.com/CaliforniaWebHome/Facilities/AdvanceSearch.aspx",
+		URL:         b.url("/CaliforniaWebHome/Facilities/AdvanceSearch.aspx/GetPlaceData"),
+		Referrer:    b.url("/CaliforniaWebHome/Facilities/AdvanceSearch.aspx"),
 		MaxAge:      searchPageExpiry,
 		ContentType: "application/json",
 		Body:        body,
@@ -137,12 +152,12 @@ type rcaResponse struct {
 	Data []placeInfo `json:"d"`
 }
 
-func (b *RCaliforniaAdv) parse(bs []byte, date time.Time, q campwiz.Query) ([]campwiz.Result, error) {
-
+// parse parses a page of search results, returning available sites and the number of places seen
+func (b *RCaliforniaAdv) parse(bs []byte, date time.Time, q campwiz.Query) ([]campwiz.Result, int, error) {
 	var rr rcaResponse
 	err := json.Unmarshal(bs, &rr)
 	if err != nil {
-		return nil, fmt.Errorf("unmarshal: %w", err)
+		return nil, 0, fmt.Errorf("unmarshal: %w", err)
 	}
 
 	klog.V(2).Infof("unmarshalled data: %+v", rr)
@@ -163,6 +178,8 @@ func (b *RCaliforniaAdv) parse(bs []byte, date time.Time, q campwiz.Query) ([]ca
 			URL:          p.URL,
 			Features:     mangle.Features(p.Highlights),
 			Distance:     float64(p.Distance),
+			Lat:          p.Latitude,
+			Lon:          p.Longitude,
 			ImageURL:     p.ImageURL,
 			Availability: []campwiz.Availability{},
 		}
@@ -201,10 +218,52 @@ func (b *RCaliforniaAdv) parse(bs []byte, date time.Time, q campwiz.Query) ([]ca
 		results = append(results, r)
 	}
 
-	return results, nil
+	return results, len(rr.Data), nil
 }
 
 // avail returns sites available on a single date
-func (b *RCaliforniaAdv) avail(q campwiz.Query, d time.Time) ([]campwiz.Result, error) {
-	return nil, nil
+func (b *RCaliforniaAdv) avail(ctx context.Context, q campwiz.Query, d time.Time) ([]campwiz.Result, error) {
+	var results []campwiz.Result
+
+	for i := 0; i < maxPages; i++ {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+
+		req, err := b.req(q, d, i)
+		if err != nil {
+			return nil, fmt.Errorf("request: %w", err)
+		}
+
+		resp, err := cache.FetchContext(ctx, req, b.store)
+		if err != nil {
+			return nil, fmt.Errorf("fetch: %w", err)
+		}
+		if err := statusError(resp); err != nil {
+			invalidate(b.store, resp)
+			return nil, err
+		}
+
+		prs, places, err := b.parse(resp.Body, d, q)
+		if err != nil {
+			invalidate(b.store, resp)
+			return nil, fmt.Errorf("parse: %w, content: %s", err, resp.Body)
+		}
+
+		results = append(results, prs...)
+
+		if places < rcaPageSize {
+			break
+		}
+
+		if !resp.Cached {
+			klog.V(1).Infof("Previous request was uncached, sleeping ...")
+			if err := sleep(ctx, uncachedDelay); err != nil {
+				return results, err
+			}
+		}
+	}
+
+	klog.Infof("returning %d results", len(results))
+	return results, nil
 }
@@ -1,6 +1,7 @@
 package backend
 
 import (
+	"context"
 	"io/ioutil"
 	"strings"
 	"testing"
@@ -25,7 +26,7 @@ func TestRCaliforniaAdvReq(t *testing.T) {
 		MaxDistance: 100,
 	}
 
-	got, err := rc.req(q, date)
+	got, err := rc.req(q, date, 2)
 	if err != nil {
 		t.Fatalf("error: %v", err)
 	}
@@ -38,7 +39,7 @@ func TestRCaliforniaAdvReq(t *testing.T) {
 		Referrer:    "https://www.reservecalifornia.com/CaliforniaWebHome/Facilities/AdvanceSearch.aspx",
 		MaxAge:      time.Duration(6 * time.Hour),
 		ContentType: "application/json",
-		Body:        []byte(`{"googlePlaceSearchParameters":{"Latitude":"37.17159","Longitude":"-122.22203","South":37.00781829886819,"North":37.335007514028106,"East":-121.96076138427298,"West":-122.48329861572044,"Filter":true,"BackToHome","ZoomLevel":9,"CenterLatitude":37.17159,"CenterLongitude":-122.22203,"ChangeDragandZoom":true,"BacktoFacility":true,"ChooseActivity":null,"IsFilterClick":false,"AvailabilitySearchParams":{"RegionId":0,"PlaceId":[","FacilityId":0,"StartDate":"01/04/2021","Nights":"1","CategoryId":0,"UnitTypeIds","UnitTypesCategory","ShowOnlyAdaUnits":false,"ShowOnlyTentSiteUnits":"false","ShowOnlyRvSiteUnits":"false","MinimumVehicleLength":"0","PageIndex":0,"PageSize","Page1","NoOfRecords":100,"ShowSiteUnitsName":"0","Autocomplitename":"Big Basin Redwoods SP","ParkFinder","ParkCategory":8,"ChooseActivity":"1","IsPremium":false},"IsFacilityLevel":false,"PlaceIdFacilityLevel":0,"MapboxPlaceid","Screenresolution":1421}'`),
+		Body:        []byte(`{"googlePlaceSearchParameters":{"Latitude":"37.4092","Longitude":"-122.0724","Filter":false,"ZoomLevel":6,"AvailabilitySearchParams":{"CategoryId":0,"ChooseActivity":0,"NoOfRecords":100,"Page1":0,"PageIndex":2,"PageSize":100,"ParkCategory":0,"StartDate":"02-12-2021","Nights":"4"}},"ScreenResolution":1422}`),
 	}
 
 	if diff := cmp.Diff(want, got); diff != "" {
@@ -64,11 +65,15 @@ func TestRCaliforniaAdvParse(t *testing.T) {
 		MaxDistance: 100,
 	}
 
-	got, err := ra.parse(bs, date, q)
+	got, places, err := ra.parse(bs, date, q)
 	if err != nil {
 		t.Fatalf("error: %v", err)
 	}
 
+	if places != 5 {
+		t.Errorf("got places: %d, want: %d", places, 5)
+	}
+
 	want := []campwiz.Result{
 		{
 			ResURL:   "https://www.reservecalifornia.com/",
@@ -125,3 +130,53 @@ func TestRCaliforniaAdvParse(t *testing.T) {
 		t.Errorf("parseResp() mismatch (-want +got):\n%s", diff)
 	}
 }
+
+func TestRCaliforniaAdvList(t *testing.T) {
+	orig := rcaPageSize
+	defer func() { rcaPageSize = orig }()
+	rcaPageSize = 5
+
+	cs := &fakeStore{seen: map[string][]byte{}}
+	b := &RCaliforniaAdv{store: cs}
+
+	date, err := time.Parse("2006-01-02", "2021-02-12")
+	if err != nil {
+		t.Fatalf("time parse: %v", err)
+	}
+	q := campwiz.Query{
+		Dates:       []time.Time{date},
+		StayLength:  4,
+		Lon:         -122.07237049999999,
+		Lat:         37.4092297,
+		MaxDistance: 100,
+	}
+
+	bs, err := ioutil.ReadFile("testdata/rca.json")
+	if err != nil {
+		t.Fatalf("readfile: %v", err)
+	}
+
+	// The first page is full, so a second page must be requested
+	for page, body := range [][]byte{bs, []byte(`{"d":[]}`)} {
+		req, err := b.req(q, date, page)
+		if err != nil {
+			t.Fatalf("req: %v", err)
+		}
+		seed(t, cs, req, body)
+	}
+
+	got, err := b.List(context.Background(), q)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+
+	gotNames := []string{}
+	for _, r := range got {
+		gotNames = append(gotNames, r.Name)
+	}
+
+	want := []string{"Portola Redwoods SP"}
+	if diff := cmp.Diff(want, gotNames); diff != "" {
+		t.Errorf("List() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,397 @@
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http/cookiejar"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/tstromberg/campwiz/pkg/cache"
+	"github.com/tstromberg/campwiz/pkg/campwiz"
+	"github.com/tstromberg/campwiz/pkg/geo"
+	"github.com/tstromberg/campwiz/pkg/mangle"
+	"k8s.io/klog/v2"
+)
+
+var (
+	// recGovPageSize is the number of campgrounds to request per search page
+	recGovPageSize = 50
+
+	// recGovKinds maps campsite types to kinds. Unknown types are guessed by mangle.SiteKind
+	recGovKinds = map[string]campwiz.SiteKind{
+		"STANDARD NONELECTRIC":             campwiz.Standard,
+		"STANDARD ELECTRIC":                campwiz.RV,
+		"TENT ONLY NONELECTRIC":            campwiz.Tent,
+		"TENT ONLY ELECTRIC":               campwiz.Tent,
+		"RV NONELECTRIC":                   campwiz.RV,
+		"RV ELECTRIC":                      campwiz.RV,
+		"CABIN NONELECTRIC":                campwiz.Lodging,
+		"CABIN ELECTRIC":                   campwiz.Lodging,
+		"YURT":                             campwiz.Lodging,
+		"SHELTER NONELECTRIC":              campwiz.Lodging,
+		"GROUP STANDARD NONELECTRIC":       campwiz.Group,
+		"GROUP STANDARD ELECTRIC":          campwiz.Group,
+		"GROUP STANDARD AREA NONELECTRIC":  campwiz.Group,
+		"GROUP TENT ONLY AREA NONELECTRIC": campwiz.Group,
+		"GROUP RV AREA NONELECTRIC":        campwiz.Group,
+		"GROUP SHELTER NONELECTRIC":        campwiz.Group,
+		"GROUP EQUESTRIAN":                 campwiz.Equestrian,
+		"EQUESTRIAN NONELECTRIC":           campwiz.Equestrian,
+		"EQUESTRIAN ELECTRIC":              campwiz.Equestrian,
+		"WALK TO":                          campwiz.Walk,
+		"HIKE TO":                          campwiz.Walk,
+		"BOAT IN":                          campwiz.Boat,
+	}
+)
+
+// RecreationGov handles Recreation.gov (RIDB) queries
+type RecreationGov struct {
+	store cache.Store
+	jar   *cookiejar.Jar
+	// base overrides the root URL of the site
+	base string
+}
+
+// Name is a human readable name
+func (b *RecreationGov) Name() string {
+	return "Recreation.gov"
+}
+
+// List lists available sites
+func (b *RecreationGov) List(ctx context.Context, q campwiz.Query) ([]campwiz.Result, error) {
+	klog.Infof("RecreationGov.List: %+v", q)
+	cgs, err := b.campgrounds(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("campgrounds: %w", err)
+	}
+
+	var res []campwiz.Result
+	for _, d := range q.Dates {
+		if err := ctx.Err(); err != nil {
+			return res, err
+		}
+
+		rs, err := b.avail(ctx, q, d, cgs)
+		if err != nil {
+			return res, fmt.Errorf("avail: %w", err)
+		}
+		res = append(res, rs...)
+	}
+
+	return mergeDates(res), nil
+}
+
+// url is the root URL to use for requests
+func (b *RecreationGov) url(s string) string {
+	return root(b.base, "https://"+"www."+"recreation"+".gov") + s
+}
+
+// searchReq generates a request for campgrounds near the query location
+func (b *RecreationGov) searchReq(q campwiz.Query, start int) cache.Request {
+	return cache.Request{
+		Method:   "GET",
+		URL:      b.url("/api/search"),
+		Referrer: b.url("/"),
+		Jar:      b.jar,
+		MaxAge:   searchPageExpiry,
+		Form: url.Values{
+			"lat":    {fmt.Sprintf("%3.3f", q.Lat)},
+			"lng":    {fmt.Sprintf("%3.3f", q.Lon)},
+			"radius": {strconv.Itoa(q.MaxDistance)},
+			"fq":     {"entity_type:campground"},
+			"sort":   {"distance"},
+			"size":   {strconv.Itoa(recGovPageSize)},
+			"start":  {strconv.Itoa(start)},
+		},
+	}
+}
+
+// monthReq generates a request for a campgrounds availability within a month
+func (b *RecreationGov) monthReq(id string, month time.Time) cache.Request {
+	return cache.Request{
+		Method:   "GET",
+		URL:      b.url("/api/camps/availability/campground/" + id + "/month"),
+		Referrer: b.url("/camping/campgrounds/" + id),
+		Jar:      b.jar,
+		MaxAge:   searchPageExpiry,
+		Form: url.Values{
+			"start_date": {month.Format("2006-01-02") + "T00:00:00.000Z"},
+		},
+	}
+}
+
+type rgActivity struct {
+	Name string `json:"activity_name"`
+}
+
+type rgCampground struct {
+	ID          string       `json:"entity_id"`
+	Type        string       `json:"entity_type"`
+	Name        string       `json:"name"`
+	Description string       `json:"description"`
+	Latitude    string       `json:"latitude"`
+	Longitude   string       `json:"longitude"`
+	ImageURL    string       `json:"preview_image_url"`
+	Reservable  bool         `json:"reservable"`
+	Activities  []rgActivity `json:"activities"`
+}
+
+type rgSearchResponse struct {
+	Results []rgCampground `json:"results"`
+	Total   int            `json:"total"`
+}
+
+type rgCampsite struct {
+	ID             string            `json:"campsite_id"`
+	Site           string            `json:"site"`
+	Loop           string            `json:"loop"`
+	Type           string            `json:"campsite_type"`
+	TypeOfUse      string            `json:"type_of_use"`
+	MaxNumPeople   int               `json:"max_num_people"`
+	Availabilities map[string]string `json:"availabilities"`
+}
+
+type rgMonthResponse struct {
+	Campsites map[string]rgCampsite `json:"campsites"`
+}
+
+// parseSearch parses the campground search response, returning reservable campgrounds and the total count
+func (b *RecreationGov) parseSearch(bs []byte) ([]rgCampground, int, error) {
+	var sr rgSearchResponse
+	err := json.Unmarshal(bs, &sr)
+	if err != nil {
+		return nil, 0, fmt.Errorf("unmarshal: %w", err)
+	}
+	klog.V(2).Infof("unmarshalled data: %+v", sr)
+
+	var cgs []rgCampground
+	for _, c := range sr.Results {
+		if c.Type != "campground" || !c.Reservable {
+			continue
+		}
+		cgs = append(cgs, c)
+	}
+	return cgs, sr.Total, nil
+}
+
+// parseMonth parses the monthly availability response
+func (b *RecreationGov) parseMonth(bs []byte) (map[string]rgCampsite, error) {
+	var mr rgMonthResponse
+	err := json.Unmarshal(bs, &mr)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal: %w", err)
+	}
+	klog.V(2).Infof("unmarshalled data: %+v", mr)
+	return mr.Campsites, nil
+}
+
+// kind returns the kind of site for a campsite
+func (b *RecreationGov) kind(s rgCampsite) campwiz.SiteKind {
+	if s.TypeOfUse == "Day" {
+		return campwiz.Day
+	}
+	if k, ok := recGovKinds[s.Type]; ok {
+		return k
+	}
+	return mangle.SiteKind(s.Loop, s.Type, s.Site)
+}
+
+// result returns a result for a campground, populated with sites available for the entire stay
+func (b *RecreationGov) result(c rgCampground, sites map[string]rgCampsite, date time.Time, q campwiz.Query) campwiz.Result {
+	r := campwiz.Result{
+		ResURL:   b.url("/"),
+		ResID:    c.ID,
+		Name:     mangle.Title(c.Name),
+		Desc:     c.Description,
+		URL:      b.url("/camping/campgrounds/" + c.ID),
+		ImageURL: c.ImageURL,
+	}
+
+	lat, latErr := strconv.ParseFloat(c.Latitude, 64)
+	lon, lonErr := strconv.ParseFloat(c.Longitude, 64)
+	if latErr == nil && lonErr == nil {
+		r.Distance = geo.MilesApart(q.Lat, q.Lon, lat, lon)
+		r.Lat = lat
+		r.Lon = lon
+	}
+
+	for _, a := range c.Activities {
+		r.Features = append(r.Features, mangle.Title(a.Name))
+	}
+
+	nights := q.StayLength
+	if nights < 1 {
+		nights = 1
+	}
+
+	avail := map[string]*campwiz.Availability{}
+
+	for _, s := range sites {
+		if s.Type == "MANAGEMENT" {
+			continue
+		}
+
+		open := true
+		for i := 0; i < nights; i++ {
+			night := date.AddDate(0, 0, i).Format("2006-01-02") + "T00:00:00Z"
+			if s.Availabilities[night] != "Available" {
+				open = false
+				break
+			}
+		}
+		if !open {
+			continue
+		}
+
+		kind := b.kind(s)
+		site := campwiz.Site{
+			ID:           s.Site,
+			Loop:         s.Loop,
+			MaxOccupancy: s.MaxNumPeople,
+			Hookups:      mangle.Hookups(s.Type),
+			Accessible:   kind.Accessible(),
+		}
+
+		key := fmt.Sprintf("%s=%s", s.Type, kind)
+		if a, ok := avail[key]; ok {
+			a.SpotCount++
+			a.Sites = append(a.Sites, site)
+			continue
+		}
+
+		avail[key] = &campwiz.Availability{
+			Kind:      kind,
+			Name:      r.Name,
+			Desc:      mangle.Title(s.Type),
+			SpotCount: 1,
+			Sites:     []campwiz.Site{site},
+			Date:      date,
+			URL:       b.url("/camping/campgrounds/" + c.ID),
+		}
+	}
+
+	for _, a := range avail {
+		// Campsites are returned in map order
+		sort.Slice(a.Sites, func(i, j int) bool { return a.Sites[i].ID < a.Sites[j].ID })
+		r.Availability = append(r.Availability, *a)
+	}
+
+	sort.Slice(r.Availability, func(i, j int) bool {
+		return string(r.Availability[i].Kind)+r.Availability[i].Desc < string(r.Availability[j].Kind)+r.Availability[j].Desc
+	})
+	return r
+}
+
+// campgrounds returns reservable campgrounds near the query location
+func (b *RecreationGov) campgrounds(ctx context.Context, q campwiz.Query) ([]rgCampground, error) {
+	var cgs []rgCampground
+
+	for i := 0; i < maxPages; i++ {
+		resp, err := cache.FetchContext(ctx, b.searchReq(q, i*recGovPageSize), b.store)
+		if err != nil {
+			return nil, fmt.Errorf("fetch: %w", err)
+		}
+		if err := statusError(resp); err != nil {
+			invalidate(b.store, resp)
+			return nil, err
+		}
+
+		pcgs, total, err := b.parseSearch(resp.Body)
+		if err != nil {
+			invalidate(b.store, resp)
+			return nil, fmt.Errorf("parse: %w, content: %s", err, resp.Body)
+		}
+		cgs = append(cgs, pcgs...)
+
+		if (i+1)*recGovPageSize >= total {
+			break
+		}
+
+		if !resp.Cached {
+			if err := sleep(ctx, uncachedDelay); err != nil {
+				return cgs, err
+			}
+		}
+	}
+
+	klog.Infof("found %d reservable campgrounds", len(cgs))
+	return cgs, nil
+}
+
+// sites returns the campsites for a campground, with availability for every month of the stay merged together
+func (b *RecreationGov) sites(ctx context.Context, id string, q campwiz.Query, d time.Time) (map[string]rgCampsite, error) {
+	sites := map[string]rgCampsite{}
+
+	first := time.Date(d.Year(), d.Month(), 1, 0, 0, 0, 0, time.UTC)
+	last := endDate(d, q.StayLength-1)
+
+	for m := first; !m.After(last); m = m.AddDate(0, 1, 0) {
+		resp, err := cache.FetchContext(ctx, b.monthReq(id, m), b.store)
+		if err != nil {
+			return nil, fmt.Errorf("fetch: %w", err)
+		}
+		if err := statusError(resp); err != nil {
+			invalidate(b.store, resp)
+			return nil, err
+		}
+
+		ms, err := b.parseMonth(resp.Body)
+		if err != nil {
+			invalidate(b.store, resp)
+			return nil, fmt.Errorf("parse: %w, content: %s", err, resp.Body)
+		}
+
+		for sid, s := range ms {
+			prev, ok := sites[sid]
+			if !ok {
+				sites[sid] = s
+				continue
+			}
+			// An earlier month may have listed the campsite without any availability
+			if prev.Availabilities == nil {
+				prev.Availabilities = map[string]string{}
+			}
+			for k, v := range s.Availabilities {
+				prev.Availabilities[k] = v
+			}
+			sites[sid] = prev
+		}
+
+		if !resp.Cached {
+			if err := sleep(ctx, uncachedDelay); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return sites, nil
+}
+
+// avail lists sites available on a single date
+func (b *RecreationGov) avail(ctx context.Context, q campwiz.Query, d time.Time, cgs []rgCampground) ([]campwiz.Result, error) {
+	var results []campwiz.Result
+
+	for _, c := range cgs {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+
+		sites, err := b.sites(ctx, c.ID, q, d)
+		if err != nil {
+			return results, fmt.Errorf("%s: %w", c.Name, err)
+		}
+
+		r := b.result(c, sites, d, q)
+		if len(r.Availability) == 0 {
+			continue
+		}
+
+		klog.Infof("%s is available: %+v", r.Name, r)
+		results = append(results, r)
+	}
+
+	return results, nil
+}
@@ -0,0 +1,143 @@
+package backend
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tstromberg/campwiz/pkg/campwiz"
+)
+
+func TestRecreationGovParseSearch(t *testing.T) {
+	b := &RecreationGov{}
+
+	bs, err := ioutil.ReadFile("testdata/recgov_search.json")
+	if err != nil {
+		t.Fatalf("readfile: %v", err)
+	}
+
+	got, total, err := b.parseSearch(bs)
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	if total != 3 {
+		t.Errorf("got total: %d, want: %d", total, 3)
+	}
+
+	gotIDs := []string{}
+	for _, c := range got {
+		gotIDs = append(gotIDs, c.ID)
+	}
+
+	if diff := cmp.Diff([]string{"232447"}, gotIDs); diff != "" {
+		t.Errorf("parseSearch() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestRecreationGovResult(t *testing.T) {
+	b := &RecreationGov{}
+
+	bs, err := ioutil.ReadFile("testdata/recgov_search.json")
+	if err != nil {
+		t.Fatalf("readfile: %v", err)
+	}
+	cgs, _, err := b.parseSearch(bs)
+	if err != nil {
+		t.Fatalf("parse search: %v", err)
+	}
+
+	bs, err = ioutil.ReadFile("testdata/recgov_month.json")
+	if err != nil {
+		t.Fatalf("readfile: %v", err)
+	}
+	sites, err := b.parseMonth(bs)
+	if err != nil {
+		t.Fatalf("parse month: %v", err)
+	}
+
+	date, err := time.Parse("2006-01-02", "2021-02-12")
+	if err != nil {
+		t.Fatalf("time parse: %v", err)
+	}
+	q := campwiz.Query{
+		StayLength:  2,
+		Lon:         -122.07237049999999,
+		Lat:         37.4092297,
+		MaxDistance: 200,
+	}
+
+	got := b.result(cgs[0], sites, date, q)
+
+	url := "https://www.recreation.gov/camping/campgrounds/232447"
+	want := campwiz.Result{
+		ResURL:   "https://www.recreation.gov/",
+		ResID:    "232447",
+		Name:     "Upper Pines",
+		Distance: 139.21370486200254,
+		Lat:      37.7362,
+		Lon:      -119.5637,
+		Desc:     "Upper Pines Campground is located in the heart of Yosemite Valley, near the Happy Isles trailhead.",
+		URL:      url,
+		ImageURL: "https://cdn.recreation.gov/public/2019/11/20/00/19/232447_beeff1bb-59b8-4a87-8a5b-1e3f3b3b2a6c_700.jpg",
+		Features: []string{"Camping", "Hiking", "Fishing"},
+		Availability: []campwiz.Availability{
+			{Kind: campwiz.Standard, Name: "Upper Pines", Desc: "Standard Nonelectric", SpotCount: 3, Date: date, URL: url, Sites: []campwiz.Site{
+				{ID: "001", Loop: "Upper Pines", MaxOccupancy: 6},
+				{ID: "002", Loop: "Upper Pines", MaxOccupancy: 6},
+				{ID: "003", Loop: "Upper Pines", MaxOccupancy: 6},
+			}},
+			{Kind: campwiz.RV, Name: "Upper Pines", Desc: "RV Nonelectric", SpotCount: 1, Date: date, URL: url, Sites: []campwiz.Site{
+				{ID: "004", Loop: "Upper Pines", MaxOccupancy: 6},
+			}},
+			{Kind: campwiz.Day, Name: "Upper Pines", Desc: "Standard Nonelectric", SpotCount: 1, Date: date, URL: url, Sites: []campwiz.Site{
+				{ID: "P1", Loop: "Upper Pines Picnic", MaxOccupancy: 30},
+			}},
+			{Kind: campwiz.Group, Name: "Upper Pines", Desc: "Group Standard Nonelectric", SpotCount: 1, Date: date, URL: url, Sites: []campwiz.Site{
+				{ID: "G1", Loop: "Upper Pines Group", MaxOccupancy: 30},
+			}},
+		},
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("result() mismatch (-want +got):\n%s", diff)
+	}
+
+	// Site 002 is reserved on the 14th
+	q.StayLength = 4
+	got = b.result(cgs[0], sites, date, q)
+	for _, a := range got.Availability {
+		if a.Kind == campwiz.Standard && a.SpotCount != 2 {
+			t.Errorf("4 night standard spot count = %d, want 2", a.SpotCount)
+		}
+	}
+}
+
+func TestRecreationGovSitesAcrossMonths(t *testing.T) {
+	cs := &fakeStore{seen: map[string][]byte{}}
+	b := &RecreationGov{store: cs}
+
+	date, err := time.Parse("2006-01-02", "2021-02-27")
+	if err != nil {
+		t.Fatalf("time parse: %v", err)
+	}
+	q := campwiz.Query{StayLength: 4}
+
+	// The campsite first appears without availabilities, as closed sites do
+	seed(t, cs, b.monthReq("232447", time.Date(2021, 2, 1, 0, 0, 0, 0, time.UTC)),
+		[]byte(`{"campsites": {"1": {"campsite_id": "1", "site": "001", "availabilities": null}}}`))
+	seed(t, cs, b.monthReq("232447", time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)),
+		[]byte(`{"campsites": {"1": {"campsite_id": "1", "site": "001", "availabilities": {"2021-03-01T00:00:00Z": "Available"}}}}`))
+
+	got, err := b.sites(context.Background(), "232447", q, date)
+	if err != nil {
+		t.Fatalf("sites: %v", err)
+	}
+
+	want := map[string]string{"2021-03-01T00:00:00Z": "Available"}
+	if diff := cmp.Diff(want, got["1"].Availabilities); diff != "" {
+		t.Errorf("sites() availabilities mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -2,6 +2,7 @@ package backend
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"net/http/cookiejar"
 	"net/url"
@@ -29,6 +30,8 @@ var (
 type SantaClaraCounty struct {
 	store cache.Store
 	jar   *cookiejar.Jar
+	// base overrides the root URL of the site
+	base string
 }
 
 // Name is a human readable name
@@ -37,16 +40,24 @@ func (b *SantaClaraCounty) Name() string {
 }
 
 // List lists available sites
-func (b *SantaClaraCounty) List(q campwiz.Query) ([]campwiz.Result, error) {
+func (b *SantaClaraCounty) List(ctx context.Context, q campwiz.Query) ([]campwiz.Result, error) {
 	klog.Infof("SantaClaraCounty.List: %+v", q)
-	_, err := cache.Fetch(b.startPage(), b.store)
+	start, err := cache.FetchContext(ctx, b.startPage(), b.store)
 	if err != nil {
 		return nil, fmt.Errorf("fetch start: %w", err)
 	}
+	if err := statusError(start); err != nil {
+		invalidate(b.store, start)
+		return nil, fmt.Errorf("start: %w", err)
+	}
 
 	var res []campwiz.Result
 	for _, d := range q.Dates {
-		rs, err := b.avail(q, d)
+		if err := ctx.Err(); err != nil {
+			return res, err
+		}
+
+		rs, err := b.avail(ctx, q, d, start)
 		if err != nil {
 			return res, fmt.Errorf("avail: %w", err)
 		}
@@ -56,9 +67,16 @@ func (b *SantaClaraCounty) List(q campwiz.Query) ([]campwiz.Result, error) {
 	return mergeDates(res), nil
 }
 
+// sccSiteID returns a site ID without the annotations Santa Clara County appends, such as "#1-Horse Camp Only *"
+func sccSiteID(sid string) string {
+	sid = strings.TrimRight(sid, " *")
+	sid = strings.TrimSuffix(sid, "-Horse Camp Only")
+	return strings.TrimSpace(sid)
+}
+
 // url is the root URL to use for requests
 func (b *SantaClaraCounty) url(s string) string {
-	return "https://" + "gooutsideandplay" + ".org" + s
+	return root(b.base, "https://"+"gooutsideandplay"+".org") + s
 }
 
 // req generates a search request
@@ -128,12 +146,17 @@ func (b *SantaClaraCounty) parse(bs []byte, date time.Time, q campwiz.Query) ([]
 		}
 
 		sid := s.Find(".heavy_blue").Text()
-		if name == "" {
+		if sid == "" {
 			klog.Warningf("no sid within: %s", h)
 			return
 		}
 
 		sid = strings.TrimSpace(sid)
+		id := sccSiteID(sid)
+		if id == "" {
+			klog.Warningf("blank sid within: %s", h)
+			return
+		}
 		sType := s.Find(".body_blue").Text()
 
 		klog.Infof("name: %s type: %s sid: %s", name, sType, sid)
@@ -144,22 +167,28 @@ func (b *SantaClaraCounty) parse(bs []byte, date time.Time, q campwiz.Query) ([]
 		}
 
 		sKind := mangle.SiteKind(name, sType, sid)
+		site := campwiz.Site{
+			ID:         id,
+			Hookups:    mangle.Hookups(sType),
+			Accessible: sKind.Accessible(),
+		}
 
 		// Group availability by type + kind (may differ based on site id)
 		availKey := fmt.Sprintf("%s=%s", sType, sKind)
 		a, ok := avail[name][availKey]
 		if ok {
 			a.SpotCount++
+			a.Sites = append(a.Sites, site)
 			return
 		}
 
-		// TODO: Support multiple types + populate count
 		avail[name][availKey] = &campwiz.Availability{
 			Kind:      sKind,
 			Desc:      sType,
 			Name:      name,
 			Date:      date,
 			SpotCount: 1,
+			Sites:     []campwiz.Site{site},
 			URL:       b.url(s.Find(".FilterElement a").AttrOr("href", "")),
 		}
 
@@ -191,7 +220,7 @@ func (b *SantaClaraCounty) parse(bs []byte, date time.Time, q campwiz.Query) ([]
 }
 
 // avail lists sites available on a single date
-func (b *SantaClaraCounty) avail(q campwiz.Query, d time.Time) ([]campwiz.Result, error) {
+func (b *SantaClaraCounty) avail(ctx context.Context, q campwiz.Query, d time.Time, start cache.Response) ([]campwiz.Result, error) {
 	dist := geo.MilesApart(q.Lat, q.Lon, sccCenterLat, sccCenterLon)
 	klog.Infof("searchSCC, distance to center from %f / %f is %.1f miles", q.Lat, q.Lon, dist)
 	if dist > float64(q.MaxDistance) {
@@ -199,19 +228,19 @@ func (b *SantaClaraCounty) avail(q campwiz.Query, d time.Time) ([]campwiz.Result
 		return nil, nil
 	}
 
-	_, err := cache.Fetch(b.startPage(), b.store)
-	if err != nil {
-		return nil, fmt.Errorf("fetch start: %w", err)
-	}
-
 	req := b.req(q, d)
-	resp, err := cache.Fetch(req, b.store)
+	resp, err := cache.FetchContext(ctx, req, b.store)
 	if err != nil {
 		return nil, fmt.Errorf("fetch: %w", err)
 	}
+	if err := statusError(resp); err != nil {
+		invalidate(b.store, resp, start)
+		return nil, err
+	}
 
 	prs, err := b.parse(resp.Body, d, q)
 	if err != nil {
+		invalidate(b.store, resp, start)
 		return nil, fmt.Errorf("parse: %w", err)
 	}
 
@@ -2,10 +2,12 @@ package backend
 
 import (
 	"io/ioutil"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/tstromberg/campwiz/pkg/campwiz"
 )
 
@@ -172,7 +174,58 @@ func TestSantaClaraCountyParse(t *testing.T) {
 		},
 	}
 
-	if diff := cmp.Diff(want, got); diff != "" {
+	// Individual sites are checked by TestSantaClaraCountySites
+	if diff := cmp.Diff(want, got, cmpopts.IgnoreFields(campwiz.Availability{}, "Sites")); diff != "" {
 		t.Errorf("parseResp() mismatch (-want +got):\n%s\nraw: %+v\n", diff, got)
 	}
 }
+
+func TestSantaClaraCountySites(t *testing.T) {
+	b := &SantaClaraCounty{}
+
+	date, err := time.Parse("2006-01-02", "2021-02-12")
+	if err != nil {
+		t.Fatalf("time parse: %v", err)
+	}
+
+	bs, err := ioutil.ReadFile("testdata/scc.html")
+	if err != nil {
+		t.Fatalf("readfile: %v", err)
+	}
+
+	rs, err := b.parse(bs, date, campwiz.Query{StayLength: 4})
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	got := map[string]campwiz.Site{}
+	count := 0
+	for _, r := range rs {
+		for _, a := range r.Availability {
+			if len(a.Sites) != a.SpotCount {
+				t.Errorf("%s %s: got %d sites, want %d", r.Name, a.Kind, len(a.Sites), a.SpotCount)
+			}
+			for _, s := range a.Sites {
+				if strings.TrimSpace(s.ID) == "" || strings.Contains(s.ID, "*") {
+					t.Errorf("%s: got unclean site ID %q", r.Name, s.ID)
+				}
+				got[r.Name+"/"+s.ID] = s
+				count++
+			}
+		}
+	}
+
+	want := map[string]campwiz.Site{
+		"Coyote Lake/6RV":      {ID: "6RV", Hookups: []string{"electric"}},
+		"Coyote Lake/1E ADA":   {ID: "1E ADA", Hookups: []string{"electric"}, Accessible: true},
+		"Joseph Grant Park/#6": {ID: "#6"},
+	}
+	for k, w := range want {
+		if diff := cmp.Diff(w, got[k]); diff != "" {
+			t.Errorf("site %s mismatch (-want +got):\n%s", k, diff)
+		}
+	}
+	if count < 100 {
+		t.Errorf("got %d sites, want at least 100", count)
+	}
+}
@@ -1,17 +1,21 @@
 package backend
 
 import (
+	"context"
 	"encoding/xml"
 	"fmt"
 	"math/rand"
 	"net/http/cookiejar"
 	"net/url"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/tstromberg/campwiz/pkg/cache"
 	"github.com/tstromberg/campwiz/pkg/campwiz"
 	"github.com/tstromberg/campwiz/pkg/geo"
+	"github.com/tstromberg/campwiz/pkg/mangle"
 	"k8s.io/klog/v2"
 )
 
@@ -20,12 +24,17 @@ var (
 	smcSiteIDs   = []string{"coyote-point", "huddart-park"}
 	smcCenterLat = 37.4250399
 	smcCenterLon = -122.4130398
+
+	// smcOccupancyRe matches the maximum occupancy within a site description
+	smcOccupancyRe = regexp.MustCompile(`maximum of (\d+) (?:campers|people|persons)`)
 )
 
 // SanMateoCounty handles Santa Mateo County Parks queries
 type SanMateoCounty struct {
 	store cache.Store
 	jar   *cookiejar.Jar
+	// base overrides the root URL of the site
+	base string
 }
 
 // Name is a human readable name
@@ -34,16 +43,24 @@ func (b *SanMateoCounty) Name() string {
 }
 
 // List lists available sites
-func (b *SanMateoCounty) List(q campwiz.Query) ([]campwiz.Result, error) {
+func (b *SanMateoCounty) List(ctx context.Context, q campwiz.Query) ([]campwiz.Result, error) {
 	var res []campwiz.Result
 	for _, siteID := range smcSiteIDs {
-		_, err := cache.Fetch(b.startPage(siteID), b.store)
+		start, err := cache.FetchContext(ctx, b.startPage(siteID), b.store)
 		if err != nil {
 			return nil, fmt.Errorf("fetch start: %w", err)
 		}
+		if err := statusError(start); err != nil {
+			invalidate(b.store, start)
+			return nil, fmt.Errorf("start: %w", err)
+		}
 
 		for _, d := range q.Dates {
-			rs, err := b.avail(q, d, siteID)
+			if err := ctx.Err(); err != nil {
+				return res, err
+			}
+
+			rs, err := b.avail(ctx, q, d, siteID, start)
 			if err != nil {
 				return res, fmt.Errorf("avail: %w", err)
 			}
@@ -56,7 +73,7 @@ func (b *SanMateoCounty) List(q campwiz.Query) ([]campwiz.Result, error) {
 
 // url is the root URL to use for requests
 func (b *SanMateoCounty) url(s string) string {
-	return "https://" + "secure" + ".itinio" + ".com" + "/sanmateo" + s
+	return root(b.base, "https://"+"secure"+".itinio"+".com") + "/sanmateo" + s
 }
 
 // startPage generates an initial page request
@@ -89,9 +106,57 @@ type smcSites struct {
 }
 
 type smcSite struct {
-	XMLName   xml.Name `xml:"site"`
-	SiteID    string   `xml:"siteId,attr"`
-	Available int      `xml:"avail,attr"`
+	XMLName    xml.Name `xml:"site"`
+	SiteID     string   `xml:"siteId,attr"`
+	Available  int      `xml:"avail,attr"`
+	Desc       string   `xml:"desc,attr"`
+	Electrical string   `xml:"electrical,attr"`
+	Water      string   `xml:"water,attr"`
+	Sewer      string   `xml:"sewer,attr"`
+	ADA        string   `xml:"ada,attr"`
+	MaxRV      string   `xml:"maxRV,attr"`
+	Price      string   `xml:"price,attr"`
+	// Para1 describes the site, such as "A maximum of 8 campers are allowed at this site."
+	Para1 string `xml:"para1,attr"`
+}
+
+// site returns the details of an individual San Mateo County site
+func (s smcSite) site() campwiz.Site {
+	cs := campwiz.Site{
+		ID:         s.SiteID,
+		Accessible: s.ADA == "Yes",
+	}
+
+	if s.Electrical != "" && s.Electrical != "No" {
+		cs.Hookups = append(cs.Hookups, "electric")
+	}
+	if s.Water == "Yes" {
+		cs.Hookups = append(cs.Hookups, "water")
+	}
+	if s.Sewer == "Yes" {
+		cs.Hookups = append(cs.Hookups, "sewer")
+	}
+
+	if n, err := strconv.Atoi(s.MaxRV); err == nil {
+		cs.MaxVehicleLength = n
+	}
+	if m := smcOccupancyRe.FindStringSubmatch(s.Para1); m != nil {
+		cs.MaxOccupancy, _ = strconv.Atoi(m[1])
+	}
+	return cs
+}
+
+// kind returns the kind of site: those listing a maximum RV length take vehicles, with hookups making them RV sites
+func (s smcSite) kind() campwiz.SiteKind {
+	cs := s.site()
+	switch {
+	case cs.MaxVehicleLength == 0:
+		return campwiz.Tent
+	case len(cs.Hookups) > 0:
+		return campwiz.RV
+	default:
+		return campwiz.Standard
+	}
 }
 
 func siteIDToTitle(s string) string {
@@ -115,10 +180,13 @@ func (b *SanMateoCounty) parse(bs []byte, date time.Time, q campwiz.Query, siteI
 			continue
 		}
 		a := campwiz.Availability{
-			Kind: campwiz.Tent,
-			Date: date,
-			URL:  b.url("/" + siteID),
+			Kind:          s.kind(),
+			Date:          date,
+			URL:           b.url("/" + siteID),
+			Sites:         []campwiz.Site{s.site()},
+			PricePerNight: mangle.Price(s.Price),
 		}
+		a.TotalPrice = a.PricePerNight * float64(q.StayLength)
 
 		r := campwiz.Result{
 			ResID:        siteID,
@@ -136,7 +204,7 @@ func (b *SanMateoCounty) parse(bs []byte, date time.Time, q campwiz.Query, siteI
 }
 
 // avail lists sites available on a single date / location
-func (b *SanMateoCounty) avail(q campwiz.Query, d time.Time, siteID string) ([]campwiz.Result, error) {
+func (b *SanMateoCounty) avail(ctx context.Context, q campwiz.Query, d time.Time, siteID string, start cache.Response) ([]campwiz.Result, error) {
 	dist := geo.MilesApart(q.Lat, q.Lon, smcCenterLat, smcCenterLon)
 	klog.Infof("searchSMC, distance to center from %f / %f is %.1f miles", q.Lat, q.Lon, dist)
 	if dist > float64(q.MaxDistance) {
@@ -145,13 +213,18 @@ func (b *SanMateoCounty) avail(q campwiz.Query, d time.Time, siteID string) ([]c
 	}
 
 	req := b.req(q, d, siteID)
-	resp, err := cache.Fetch(req, b.store)
+	resp, err := cache.FetchContext(ctx, req, b.store)
 	if err != nil {
 		return nil, fmt.Errorf("fetch: %w", err)
 	}
+	if err := statusError(resp); err != nil {
+		invalidate(b.store, resp, start)
+		return nil, err
+	}
 
 	prs, err := b.parse(resp.Body, d, q, siteID)
 	if err != nil {
+		invalidate(b.store, resp, start)
 		return nil, fmt.Errorf("parse: %w", err)
 	}
 
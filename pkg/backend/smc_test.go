@@ -83,6 +83,44 @@ func TestParseSMCSearchPage(t *testing.T) {
 	}
 }
 
+func TestSMCSites(t *testing.T) {
+	bs, err := ioutil.ReadFile("testdata/smc_feed.xml")
+	if err != nil {
+		t.Fatalf("readfile: %v", err)
+	}
+	date, err := time.Parse("2006-01-02", "2021-02-12")
+	if err != nil {
+		t.Fatalf("time parse: %v", err)
+	}
+
+	b := &SanMateoCounty{}
+	rs, err := b.parse(bs, date, campwiz.Query{StayLength: 2}, "coyote-point")
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	got := []campwiz.Site{}
+	for _, r := range rs {
+		for _, a := range r.Availability {
+			got = append(got, a.Sites...)
+			if a.Kind != campwiz.RV {
+				t.Errorf("%s kind = %s, want %s for sites with hookups and a maximum RV length", r.Name, a.Kind, campwiz.RV)
+			}
+			if a.PricePerNight != 45 || a.TotalPrice != 90 {
+				t.Errorf("%s price = %.2f/night, %.2f total, want 45/night, 90 total", r.Name, a.PricePerNight, a.TotalPrice)
+			}
+		}
+	}
+
+	site := func(id string) campwiz.Site {
+		return campwiz.Site{ID: id, MaxOccupancy: 8, MaxVehicleLength: 36, Hookups: []string{"electric", "water"}}
+	}
+	want := []campwiz.Site{site("1"), site("2"), site("3")}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("parse() sites mismatch (-want +got):\n%s", diff)
+	}
+}
+
 func TestSMCSiteRequest(t *testing.T) {
 	date, err := time.Parse("2006-01-02", "2021-02-12")
 	if err != nil {
@@ -3,7 +3,9 @@ package cache
 
 import (
 	"bytes"
+	"context"
 	"crypto/md5"
+	"database/sql"
 	"encoding/gob"
 	"fmt"
 	"io"
@@ -16,6 +18,7 @@ import (
 	"regexp"
 	"time"
 
+	_ "github.com/mattn/go-sqlite3" // SQLite driver for the sqlite backend
 	"github.com/moul/http2curl"
 	"github.com/peterbourgon/diskv"
 	"k8s.io/klog/v2"
@@ -31,6 +34,9 @@ var (
 	// How long to cache by default: needs to be less than session cookie (12 hours is too long)
 	RecommendedMaxAge = 4 * time.Hour
 	defaultMaxAge     = RecommendedMaxAge
+
+	// maxAgeLimit caps the maximum age of every request, if set
+	maxAgeLimit time.Duration
 )
 
 // Request defines what can be passed in as a request
@@ -53,6 +59,8 @@ type Request struct {
 	// POST info
 	ContentType string
 	Body        []byte
+	// CacheErrors allows responses with error status codes to be cached
+	CacheErrors bool
 }
 
 // Key returns a cache-key.
@@ -103,11 +111,15 @@ type Response struct {
 	MTime time.Time
 	// If entry was served from cache
 	Cached bool
+	// Key is the cache key for this response, used for invalidation
+	Key string
 }
 
+// Store is where cached responses are persisted to
 type Store interface {
 	Read(string) ([]byte, error)
 	Write(string, []byte) error
+	Delete(string) error
 }
 
 // tryCache attempts a cache-only fetch.
@@ -143,6 +155,9 @@ func applyDefaults(req Request) (Request, error) {
 	if req.MaxAge == 0 {
 		req.MaxAge = defaultMaxAge
 	}
+	if maxAgeLimit > 0 && req.MaxAge > maxAgeLimit {
+		req.MaxAge = maxAgeLimit
+	}
 	if req.Method == "" {
 		req.Method = "GET"
 	}
@@ -172,8 +187,13 @@ func applyDefaults(req Request) (Request, error) {
 	return req, nil
 }
 
-// Fetch wraps http.Get/http.Post behind a persistent ca
+// Fetch wraps http.Get/http.Post behind a persistent cache
 func Fetch(req Request, cs Store) (Response, error) {
+	return FetchContext(context.Background(), req, cs)
+}
+
+// FetchContext is like Fetch, but aborts uncached requests when ctx is done
+func FetchContext(ctx context.Context, req Request, cs Store) (Response, error) {
 	klog.V(2).Infof("incoming fetch: %+v", req)
 	req, err := applyDefaults(req)
 	if err != nil {
@@ -194,6 +214,7 @@ func Fetch(req Request, cs Store) (Response, error) {
 		klog.V(3).Infof("cached cookies: %v", res.Cookies)
 		klog.V(4).Infof("cached body: %s", res.Body)
 		res.Cached = true
+		res.Key = req.Key()
 		u, err := url.Parse(res.URL)
 		if err != nil {
 			return Response{}, err
@@ -207,7 +228,7 @@ func Fetch(req Request, cs Store) (Response, error) {
 	}
 
 	getBody := bytes.NewBuffer(req.Body)
-	hr, err := http.NewRequest(req.Method, encURL, getBody)
+	hr, err := http.NewRequestWithContext(ctx, req.Method, encURL, getBody)
 	if err != nil {
 		return res, err
 	}
@@ -243,6 +264,10 @@ func Fetch(req Request, cs Store) (Response, error) {
 	}
 
 	client := &http.Client{Jar: req.Jar}
+	// Stores such as Fixture handle the HTTP exchange themselves
+	if rt, ok := cs.(http.RoundTripper); ok {
+		client.Transport = rt
+	}
 	r, err := client.Do(hr)
 	if err != nil {
 		return res, err
@@ -261,6 +286,7 @@ func Fetch(req Request, cs Store) (Response, error) {
 		Cookies:    req.Jar.Cookies(r.Request.URL),
 		Body:       body,
 		MTime:      time.Now(),
+		Key:        req.Key(),
 	}
 
 	klog.Infof("Fetched %s, status=%d, cookies=%s, bytes=%d", req.URL, r.StatusCode, r.Cookies(), len(body))
@@ -273,6 +299,13 @@ func Fetch(req Request, cs Store) (Response, error) {
 
 	klog.V(2).Infof("body: %s", body)
 
+	if !req.CacheErrors && r.StatusCode >= 400 {
+		klog.Warningf("not caching %s: status=%d", req.URL, r.StatusCode)
+		// Nothing was stored, so there is nothing to invalidate
+		cr.Key = ""
+		return cr, nil
+	}
+
 	var buf bytes.Buffer
 	enc := gob.NewEncoder(&buf)
 	err = enc.Encode(&cr)
@@ -288,6 +321,7 @@ func Fetch(req Request, cs Store) (Response, error) {
 		err := cs.Write(req.Key(), bufBytes)
 		if err != nil {
 			klog.Errorf("unable to write %s: %v", req.Key(), err)
+			cr.Key = ""
 			return cr, nil
 		}
 	}
@@ -296,28 +330,95 @@ func Fetch(req Request, cs Store) (Response, error) {
 	return cr, nil
 }
 
+// Config is how external users configure a cache
 type Config struct {
 	MaxAge time.Duration
+	// MaxAgeLimit caps the maximum age of all requests, including those that set their own
+	MaxAgeLimit time.Duration
+	// Backend is where to persist the cache to: "disk" (default) or "sqlite".
+	// "record" and "replay" bypass the cache, recording exchanges to or replaying them from a fixture file.
+	Backend string
+	// Path is where to store the cache, or the fixture file. Defaults to a location within the users cache directory.
+	Path string
 }
 
-// New returns a new cache (hardcoded to diskv, for the moment)
-func New(c Config) (*diskv.Diskv, error) {
+// New returns a new cache store
+func New(c Config) (Store, error) {
 	defaultMaxAge = c.MaxAge
-	return initialize()
+	maxAgeLimit = c.MaxAgeLimit
+	klog.Infof("default expiry is %s (limit: %s)", defaultMaxAge, maxAgeLimit)
+
+	switch c.Backend {
+	case "", "disk":
+		return newDisk(c.Path)
+	case "sqlite":
+		return newSQLite(c.Path)
+	case "record":
+		return newFixture(c.Path, Record)
+	case "replay":
+		return newFixture(c.Path, Replay)
+	default:
+		return nil, fmt.Errorf("unknown cache backend: %q", c.Backend)
+	}
 }
 
-// initialize returns an initialized cache
-func initialize() (*diskv.Diskv, error) {
+// defaultPath returns the default location for the cache
+func defaultPath(name string) (string, error) {
 	root, err := os.UserCacheDir()
 	if err != nil {
-		return nil, fmt.Errorf("cache dir: %w", err)
+		return "", fmt.Errorf("cache dir: %w", err)
+	}
+	return filepath.Join(root, name), nil
+}
+
+// diskStore is a Store backed by diskv
+type diskStore struct {
+	*diskv.Diskv
+}
+
+// Delete removes a key from the disk cache, ignoring keys that were never stored
+func (d *diskStore) Delete(key string) error {
+	if !d.Has(key) {
+		return nil
+	}
+	return d.Erase(key)
+}
+
+// newDisk returns an initialized disk cache
+func newDisk(path string) (*diskStore, error) {
+	if path == "" {
+		p, err := defaultPath("campwiz")
+		if err != nil {
+			return nil, err
+		}
+		path = p
 	}
-	cacheDir := filepath.Join(root, "campwiz")
-	klog.Infof("cache dir is %s", cacheDir)
-	klog.Infof("default expiry is %s", defaultMaxAge)
+	klog.Infof("cache dir is %s", path)
 
-	return diskv.New(diskv.Options{
-		BasePath:     cacheDir,
+	return &diskStore{diskv.New(diskv.Options{
+		BasePath:     path,
 		CacheSizeMax: 1024 * 1024 * 1024,
-	}), nil
+	})}, nil
+}
+
+// newSQLite returns an initialized SQLite cache
+func newSQLite(path string) (*SQLStore, error) {
+	if path == "" {
+		p, err := defaultPath("campwiz.db")
+		if err != nil {
+			return nil, err
+		}
+		path = p
+	}
+	klog.Infof("cache database is %s", path)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("mkdir: %w", err)
+	}
+
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil, fmt.Errorf("open: %w", err)
+	}
+	return NewSQLStore(db)
 }
@@ -1,11 +1,13 @@
 package cache
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/http/cookiejar"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
@@ -39,6 +41,30 @@ func TestApplyDefaults(t *testing.T) {
 	}
 }
 
+func TestApplyDefaultsMaxAgeLimit(t *testing.T) {
+	defer func() { maxAgeLimit = 0 }()
+	maxAgeLimit = 15 * time.Minute
+
+	var tests = []struct {
+		in   time.Duration
+		want time.Duration
+	}{
+		{0, 15 * time.Minute},
+		{6 * time.Hour, 15 * time.Minute},
+		{time.Minute, time.Minute},
+	}
+
+	for _, tt := range tests {
+		got, err := applyDefaults(Request{URL: "/", MaxAge: tt.in})
+		if err != nil {
+			t.Fatalf("error: %v", err)
+		}
+		if got.MaxAge != tt.want {
+			t.Errorf("applyDefaults(MaxAge=%s).MaxAge = %s, want %s", tt.in, got.MaxAge, tt.want)
+		}
+	}
+}
+
 type FakeStore struct {
 	seen map[string][]byte
 }
@@ -56,6 +82,11 @@ func (f *FakeStore) Write(key string, bs []byte) error {
 	return nil
 }
 
+func (f *FakeStore) Delete(key string) error {
+	delete(f.seen, key)
+	return nil
+}
+
 func TestFetch(t *testing.T) {
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		fmt.Fprintln(w, "hi")
@@ -92,3 +123,84 @@ func TestFetch(t *testing.T) {
 		t.Errorf("applyDefaults() mismatch (-want +got):\n%s", diff)
 	}
 }
+
+func TestFetchContextCanceled(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "hi")
+	}))
+	defer ts.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	cs := &FakeStore{seen: map[string][]byte{}}
+	_, err := FetchContext(ctx, Request{URL: ts.URL}, cs)
+	if err == nil {
+		t.Errorf("expected error from canceled fetch")
+	}
+	if len(cs.seen) > 0 {
+		t.Errorf("canceled fetch was cached: %v", cs.seen)
+	}
+}
+
+func TestFetchErrorStatus(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "session expired", http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	cs := &FakeStore{seen: map[string][]byte{}}
+	got, err := Fetch(Request{URL: ts.URL}, cs)
+	if err != nil {
+		t.Fatalf("fetch error: %v", err)
+	}
+	if got.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("got status %d, want %d", got.StatusCode, http.StatusServiceUnavailable)
+	}
+	if len(cs.seen) > 0 {
+		t.Errorf("error response was cached: %v", cs.seen)
+	}
+
+	got, err = Fetch(Request{URL: ts.URL, CacheErrors: true}, cs)
+	if err != nil {
+		t.Fatalf("fetch error: %v", err)
+	}
+	if _, ok := cs.seen[got.Key]; !ok {
+		t.Errorf("%q not cached, have: %v", got.Key, cs.seen)
+	}
+
+	if err := cs.Delete(got.Key); err != nil {
+		t.Errorf("delete: %v", err)
+	}
+	got, err = Fetch(Request{URL: ts.URL, CacheErrors: true}, cs)
+	if err != nil {
+		t.Fatalf("fetch error: %v", err)
+	}
+	if got.Cached {
+		t.Errorf("expected uncached result after delete")
+	}
+}
+
+func TestDiskInvalidateErrorStatus(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "session expired", http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	cs, err := newDisk(t.TempDir())
+	if err != nil {
+		t.Fatalf("newDisk: %v", err)
+	}
+
+	req := Request{URL: ts.URL}
+	got, err := Fetch(req, cs)
+	if err != nil {
+		t.Fatalf("fetch error: %v", err)
+	}
+	if got.Key != "" {
+		t.Errorf("uncached response has key %q, want none", got.Key)
+	}
+	if err := cs.Delete(req.Key()); err != nil {
+		t.Errorf("delete of uncached %s: %v", req.Key(), err)
+	}
+}
@@ -0,0 +1,231 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"k8s.io/klog/v2"
+)
+
+// FixtureMode is how a Fixture handles requests
+type FixtureMode int
+
+const (
+	// Replay serves recorded exchanges without network access, failing on any request that was not recorded
+	Replay FixtureMode = iota
+	// Record fetches requests from the network, saving each exchange to the fixture file
+	Record
+)
+
+// Exchange is a recorded HTTP request and its response
+type Exchange struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	// Cookie is the Cookie header sent with the request. Replayed requests must send each cookie within it.
+	Cookie      string `json:"cookie,omitempty"`
+	RequestBody string `json:"request_body,omitempty"`
+
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body"`
+}
+
+// Fixture is a Store which never caches, so that every fetch is recorded to or replayed from a file of exchanges.
+// It is also the http.RoundTripper used for fetches against it.
+type Fixture struct {
+	path string
+	mode FixtureMode
+
+	mu        sync.Mutex
+	exchanges []Exchange
+	// used tracks which exchanges have been replayed, so that repeated requests replay in recorded order
+	used []bool
+}
+
+// NewFixture returns a Fixture for the exchanges in path. In Replay mode, path must exist.
+func NewFixture(path string, mode FixtureMode) (*Fixture, error) {
+	f := &Fixture{path: path, mode: mode}
+	if mode == Record {
+		return f, nil
+	}
+
+	bs, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read fixture: %w", err)
+	}
+	if err := json.Unmarshal(bs, &f.exchanges); err != nil {
+		return nil, fmt.Errorf("unmarshal %s: %w", path, err)
+	}
+	f.used = make([]bool, len(f.exchanges))
+	klog.Infof("replaying %d exchanges from %s", len(f.exchanges), path)
+	return f, nil
+}
+
+// Read never finds anything, so that every request reaches RoundTrip
+func (f *Fixture) Read(key string) ([]byte, error) {
+	return nil, fmt.Errorf("fixtures do not cache %q", key)
+}
+
+// Write discards responses
+func (f *Fixture) Write(key string, bs []byte) error {
+	return nil
+}
+
+// Delete is a no-op
+func (f *Fixture) Delete(key string) error {
+	return nil
+}
+
+// Unused returns the recorded exchanges which have not yet been replayed
+func (f *Fixture) Unused() []Exchange {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var es []Exchange
+	for i, e := range f.exchanges {
+		if !f.used[i] {
+			es = append(es, e)
+		}
+	}
+	return es
+}
+
+// RoundTrip records or replays a single HTTP request
+func (f *Fixture) RoundTrip(hr *http.Request) (*http.Response, error) {
+	var body []byte
+	if hr.Body != nil {
+		bs, err := ioutil.ReadAll(hr.Body)
+		if err != nil {
+			return nil, fmt.Errorf("read request body: %w", err)
+		}
+		hr.Body.Close()
+		body = bs
+	}
+
+	if f.mode == Record {
+		return f.record(hr, body)
+	}
+	return f.replay(hr, body)
+}
+
+// record performs a live request, appending the exchange to the fixture file
+func (f *Fixture) record(hr *http.Request, body []byte) (*http.Response, error) {
+	hr.Body = ioutil.NopCloser(bytes.NewReader(body))
+	r, err := http.DefaultTransport.RoundTrip(hr)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Body.Close()
+
+	rbody, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response body: %w", err)
+	}
+
+	e := Exchange{
+		Method:      hr.Method,
+		URL:         hr.URL.String(),
+		Cookie:      hr.Header.Get("Cookie"),
+		RequestBody: string(body),
+		StatusCode:  r.StatusCode,
+		Header:      r.Header,
+		Body:        string(rbody),
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.exchanges = append(f.exchanges, e)
+	f.used = append(f.used, true)
+	if err := f.save(); err != nil {
+		return nil, fmt.Errorf("save: %w", err)
+	}
+	klog.Infof("recorded %s %s to %s", e.Method, e.URL, f.path)
+
+	return e.response(hr), nil
+}
+
+// save writes every exchange to the fixture file
+func (f *Fixture) save() error {
+	bs, err := json.MarshalIndent(f.exchanges, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(f.path), 0o755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(f.path, append(bs, '\n'), 0o644)
+}
+
+// replay returns the first unused exchange that matches a request
+func (f *Fixture) replay(hr *http.Request, body []byte) (*http.Response, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	u := hr.URL.String()
+	for i, e := range f.exchanges {
+		if f.used[i] || e.Method != hr.Method || e.URL != u || e.RequestBody != string(body) {
+			continue
+		}
+		if missing := missingCookies(e.Cookie, hr); len(missing) > 0 {
+			return nil, fmt.Errorf("%s %s: missing recorded cookies %v", hr.Method, u, missing)
+		}
+		f.used[i] = true
+		klog.V(1).Infof("replaying %s %s from %s", e.Method, e.URL, f.path)
+		return e.response(hr), nil
+	}
+	return nil, fmt.Errorf("unexpected request, not in %s: %s %s (body: %q)", f.path, hr.Method, u, body)
+}
+
+// missingCookies returns the cookies from a recorded Cookie header which a request does not send
+func missingCookies(recorded string, hr *http.Request) []string {
+	if recorded == "" {
+		return nil
+	}
+
+	sent := map[string]bool{}
+	for _, c := range hr.Cookies() {
+		sent[c.String()] = true
+	}
+
+	var missing []string
+	rr := &http.Request{Header: http.Header{"Cookie": {recorded}}}
+	for _, c := range rr.Cookies() {
+		if !sent[c.String()] {
+			missing = append(missing, c.String())
+		}
+	}
+	return missing
+}
+
+// response returns the recorded response to a request
+func (e Exchange) response(hr *http.Request) *http.Response {
+	h := e.Header
+	if h == nil {
+		h = http.Header{}
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode)),
+		StatusCode:    e.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        h.Clone(),
+		Body:          ioutil.NopCloser(bytes.NewReader([]byte(e.Body))),
+		ContentLength: int64(len(e.Body)),
+		Request:       hr,
+	}
+}
+
+// newFixture returns a Fixture configured by the cache Config, which requires a path
+func newFixture(path string, mode FixtureMode) (*Fixture, error) {
+	if path == "" {
+		return nil, fmt.Errorf("fixture backends require a path")
+	}
+	return NewFixture(path, mode)
+}
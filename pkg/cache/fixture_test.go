@@ -0,0 +1,99 @@
+package cache
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+func TestFixtureRecordReplay(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/start" {
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc", Path: "/"})
+			fmt.Fprintln(w, "welcome")
+			return
+		}
+		c, err := r.Cookie("session")
+		if err != nil {
+			http.Error(w, "no session", http.StatusForbidden)
+			return
+		}
+		fmt.Fprintf(w, "page %s for %s\n", r.FormValue("page"), c.Value)
+	}))
+
+	path := filepath.Join(t.TempDir(), "fixture.json")
+	fetchAll := func(cs Store) []string {
+		t.Helper()
+		jar, err := cookiejar.New(nil)
+		if err != nil {
+			t.Fatalf("jar: %v", err)
+		}
+
+		var bodies []string
+		for _, req := range []Request{
+			{URL: ts.URL + "/start", Jar: jar},
+			{URL: ts.URL + "/search", Method: "POST", Jar: jar, Form: map[string][]string{"page": {"0"}}},
+			{URL: ts.URL + "/search", Method: "POST", Jar: jar, Form: map[string][]string{"page": {"1"}}},
+		} {
+			got, err := Fetch(req, cs)
+			if err != nil {
+				t.Fatalf("fetch %s: %v", req.URL, err)
+			}
+			if got.Cached {
+				t.Errorf("%s was cached", req.URL)
+			}
+			bodies = append(bodies, string(got.Body))
+		}
+		return bodies
+	}
+
+	rec, err := NewFixture(path, Record)
+	if err != nil {
+		t.Fatalf("record: %v", err)
+	}
+	want := fetchAll(rec)
+	if want[2] != "page 1 for abc\n" {
+		t.Errorf("recorded body = %q, want session cookie to be sent", want[2])
+	}
+
+	// Replay must work without the server
+	ts.Close()
+
+	rep, err := NewFixture(path, Replay)
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	got := fetchAll(rep)
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("replayed body %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+	if u := rep.Unused(); len(u) > 0 {
+		t.Errorf("unused exchanges: %+v", u)
+	}
+
+	// Every recorded exchange has been replayed, so repeating a request is unexpected
+	if _, err := Fetch(Request{URL: ts.URL + "/start"}, rep); err == nil {
+		t.Errorf("expected error for unrecorded request")
+	}
+}
+
+func TestFixtureReplayMissingCookie(t *testing.T) {
+	path := filepath.Join("testdata", "missing.json")
+	if _, err := NewFixture(path, Replay); err == nil {
+		t.Errorf("NewFixture(%s) expected error for missing file", path)
+	}
+
+	f := &Fixture{
+		path:      "inline",
+		exchanges: []Exchange{{Method: "GET", URL: "http://example.com/", Cookie: "session=abc", StatusCode: 200, Body: "hi"}},
+		used:      []bool{false},
+	}
+	if _, err := Fetch(Request{URL: "http://example.com/"}, f); err == nil {
+		t.Errorf("expected error for request without recorded cookie")
+	}
+}
@@ -0,0 +1,88 @@
+package cache
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/gob"
+	"fmt"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// sqlSchema sticks to column types understood by most SQL dialects
+var sqlSchema = []string{
+	`CREATE TABLE IF NOT EXISTS cache (
+		cache_key VARCHAR(255) NOT NULL PRIMARY KEY,
+		mtime TIMESTAMP NOT NULL,
+		value BLOB NOT NULL
+	)`,
+	`CREATE INDEX cache_mtime ON cache (mtime)`,
+}
+
+// SQLStore is a Store backed by a database/sql database
+type SQLStore struct {
+	db *sql.DB
+}
+
+// NewSQLStore returns a SQL-backed store, creating the schema if necessary
+func NewSQLStore(db *sql.DB) (*SQLStore, error) {
+	if _, err := db.Exec(sqlSchema[0]); err != nil {
+		return nil, fmt.Errorf("create table: %w", err)
+	}
+
+	// Not every dialect supports CREATE INDEX IF NOT EXISTS, so tolerate failure here.
+	if _, err := db.Exec(sqlSchema[1]); err != nil {
+		klog.V(1).Infof("create index: %v", err)
+	}
+
+	return &SQLStore{db: db}, nil
+}
+
+// Read returns the value stored for a key
+func (s *SQLStore) Read(key string) ([]byte, error) {
+	var bs []byte
+	err := s.db.QueryRow(`SELECT value FROM cache WHERE cache_key = ?`, key).Scan(&bs)
+	if err != nil {
+		return nil, fmt.Errorf("select %q: %w", key, err)
+	}
+	return bs, nil
+}
+
+// Write stores the value for a key, along with the modification time of the response it encodes
+func (s *SQLStore) Write(key string, bs []byte) error {
+	mtime := time.Now()
+
+	var res Response
+	if err := gob.NewDecoder(bytes.NewReader(bs)).Decode(&res); err != nil {
+		klog.Warningf("%s is not an encoded response, using current time: %v", key, err)
+	} else {
+		mtime = res.MTime
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+
+	// DELETE+INSERT rather than an upsert, as the syntax for the latter varies between dialects
+	if _, err := tx.Exec(`DELETE FROM cache WHERE cache_key = ?`, key); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("delete %q: %w", key, err)
+	}
+
+	if _, err := tx.Exec(`INSERT INTO cache (cache_key, mtime, value) VALUES (?, ?, ?)`, key, mtime.UTC(), bs); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("insert %q: %w", key, err)
+	}
+
+	return tx.Commit()
+}
+
+// Delete removes a key from the store
+func (s *SQLStore) Delete(key string) error {
+	if _, err := s.db.Exec(`DELETE FROM cache WHERE cache_key = ?`, key); err != nil {
+		return fmt.Errorf("delete %q: %w", key, err)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package cache
+
+import (
+	"database/sql"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func newTestSQLStore(t *testing.T) (*SQLStore, *sql.DB) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "sqlstore")
+	if err != nil {
+		t.Fatalf("tempdir: %v", err)
+	}
+
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "cache.db"))
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	t.Cleanup(func() {
+		db.Close()
+		os.RemoveAll(dir)
+	})
+
+	s, err := NewSQLStore(db)
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+
+	// Schema creation must be safe to repeat
+	if _, err := NewSQLStore(db); err != nil {
+		t.Fatalf("new store again: %v", err)
+	}
+	return s, db
+}
+
+func TestSQLStoreReadWrite(t *testing.T) {
+	s, _ := newTestSQLStore(t)
+
+	if _, err := s.Read("missing"); err == nil {
+		t.Errorf("expected error reading missing key")
+	}
+
+	for _, v := range []string{"first", "second"} {
+		if err := s.Write("key", []byte(v)); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+
+		got, err := s.Read("key")
+		if err != nil {
+			t.Fatalf("read: %v", err)
+		}
+		if string(got) != v {
+			t.Errorf("got %q, want %q", got, v)
+		}
+	}
+
+	if err := s.Delete("key"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := s.Read("key"); err == nil {
+		t.Errorf("expected error reading deleted key")
+	}
+}
+
+func TestSQLStoreFetch(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "hi")
+	}))
+	defer ts.Close()
+
+	s, db := newTestSQLStore(t)
+
+	want, err := Fetch(Request{URL: ts.URL}, s)
+	if err != nil {
+		t.Fatalf("fetch error: %v", err)
+	}
+	want.Cached = true
+
+	got, err := Fetch(Request{URL: ts.URL}, s)
+	if err != nil {
+		t.Fatalf("fetch error: %v", err)
+	}
+
+	if diff := cmp.Diff(want, got, cmpopts.IgnoreUnexported(cookiejar.Jar{})); diff != "" {
+		t.Errorf("Fetch() mismatch (-want +got):\n%s", diff)
+	}
+
+	var mtime time.Time
+	if err := db.QueryRow(`SELECT mtime FROM cache`).Scan(&mtime); err != nil {
+		t.Fatalf("select mtime: %v", err)
+	}
+	if !mtime.Equal(want.MTime) {
+		t.Errorf("stored mtime = %s, want %s", mtime, want.MTime)
+	}
+}
@@ -0,0 +1,131 @@
+package campwiz
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// WeekdayNames maps user-facing names to days of the week
+var WeekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// ParseWeekdays converts day names such as "fri" or "Saturday" to weekdays
+func ParseWeekdays(names []string) ([]time.Weekday, error) {
+	ws := []time.Weekday{}
+	for _, n := range names {
+		n = strings.ToLower(strings.TrimSpace(n))
+		if len(n) > 3 {
+			n = n[0:3]
+		}
+		w, ok := WeekdayNames[n]
+		if !ok {
+			return nil, fmt.Errorf("unknown day of week %q", n)
+		}
+		ws = append(ws, w)
+	}
+	return ws, nil
+}
+
+// ArrivalDates returns the explicit dates of a query, along with any dates within its window that fall on a requested weekday
+func (q Query) ArrivalDates() []time.Time {
+	seen := map[time.Time]bool{}
+	ds := []time.Time{}
+
+	add := func(t time.Time) {
+		t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		if !seen[t] {
+			seen[t] = true
+			ds = append(ds, t)
+		}
+	}
+
+	for _, d := range q.Dates {
+		add(d)
+	}
+
+	if !q.From.IsZero() {
+		until := q.Until
+		if until.IsZero() {
+			until = q.From
+		}
+
+		want := map[time.Weekday]bool{}
+		for _, w := range q.Weekdays {
+			want[w] = true
+		}
+
+		for d := q.From; !d.After(until); d = d.AddDate(0, 0, 1) {
+			if len(want) == 0 || want[d.Weekday()] {
+				add(d)
+			}
+		}
+	}
+
+	sort.Slice(ds, func(i, j int) bool { return ds[i].Before(ds[j]) })
+	return ds
+}
+
+// WeekendOf returns the Friday within the Monday to Sunday week of a date
+func WeekendOf(t time.Time) time.Time {
+	offset := int(time.Friday - t.Weekday())
+	if t.Weekday() == time.Sunday {
+		offset = -2
+	}
+	d := t.AddDate(0, 0, offset)
+	return time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// Weekend is a set of results with availability around a single weekend
+type Weekend struct {
+	Friday  time.Time
+	Results []Result
+}
+
+// GroupByWeekend splits results by weekend, keeping the original order of results within each weekend
+func GroupByWeekend(rs []Result) []Weekend {
+	byFriday := map[time.Time]*Weekend{}
+	ws := []*Weekend{}
+
+	for _, r := range rs {
+		var order []time.Time
+		avail := map[time.Time][]Availability{}
+
+		for _, a := range r.Availability {
+			f := WeekendOf(a.Date)
+			if _, ok := avail[f]; !ok {
+				order = append(order, f)
+			}
+			avail[f] = append(avail[f], a)
+		}
+
+		for _, f := range order {
+			w, ok := byFriday[f]
+			if !ok {
+				w = &Weekend{Friday: f}
+				byFriday[f] = w
+				ws = append(ws, w)
+			}
+
+			wr := r
+			wr.Availability = avail[f]
+			w.Results = append(w.Results, wr)
+		}
+	}
+
+	sort.Slice(ws, func(i, j int) bool { return ws[i].Friday.Before(ws[j].Friday) })
+
+	out := []Weekend{}
+	for _, w := range ws {
+		out = append(out, *w)
+	}
+	return out
+}
@@ -0,0 +1,114 @@
+package campwiz
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func day(s string) time.Time {
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestArrivalDates(t *testing.T) {
+	var tests = []struct {
+		name string
+		in   Query
+		out  []string
+	}{
+		{"explicit", Query{Dates: []time.Time{day("2021-02-19"), day("2021-02-12")}}, []string{"2021-02-12", "2021-02-19"}},
+		{"single day window", Query{From: day("2021-02-12")}, []string{"2021-02-12"}},
+		{"window", Query{From: day("2021-02-12"), Until: day("2021-02-15")}, []string{"2021-02-12", "2021-02-13", "2021-02-14", "2021-02-15"}},
+		{
+			"fridays and saturdays",
+			Query{From: day("2021-02-10"), Until: day("2021-02-28"), Weekdays: []time.Weekday{time.Friday, time.Saturday}},
+			[]string{"2021-02-12", "2021-02-13", "2021-02-19", "2021-02-20", "2021-02-26", "2021-02-27"},
+		},
+		{
+			"window and dates",
+			Query{Dates: []time.Time{day("2021-02-19"), day("2021-03-01")}, From: day("2021-02-10"), Until: day("2021-02-28"), Weekdays: []time.Weekday{time.Friday}},
+			[]string{"2021-02-12", "2021-02-19", "2021-02-26", "2021-03-01"},
+		},
+		{"backwards", Query{From: day("2021-02-12"), Until: day("2021-02-01")}, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []string{}
+			for _, d := range tt.in.ArrivalDates() {
+				got = append(got, d.Format("2006-01-02"))
+			}
+			if diff := cmp.Diff(tt.out, got); diff != "" {
+				t.Errorf("ArrivalDates() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestParseWeekdays(t *testing.T) {
+	got, err := ParseWeekdays([]string{"fri", "Saturday", " SUN"})
+	if err != nil {
+		t.Fatalf("ParseWeekdays: %v", err)
+	}
+	want := []time.Weekday{time.Friday, time.Saturday, time.Sunday}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ParseWeekdays() mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, err := ParseWeekdays([]string{"someday"}); err == nil {
+		t.Errorf("ParseWeekdays(someday) = nil error, want error")
+	}
+}
+
+func TestWeekendOf(t *testing.T) {
+	for _, d := range []string{"2021-02-08", "2021-02-11", "2021-02-12", "2021-02-13", "2021-02-14"} {
+		if got := WeekendOf(day(d)).Format("2006-01-02"); got != "2021-02-12" {
+			t.Errorf("WeekendOf(%s) = %s, want 2021-02-12", d, got)
+		}
+	}
+}
+
+func TestGroupByWeekend(t *testing.T) {
+	rs := []Result{
+		{
+			Name: "A",
+			Availability: []Availability{
+				{Date: day("2021-02-19"), Kind: Tent},
+				{Date: day("2021-02-12"), Kind: Tent},
+				{Date: day("2021-02-13"), Kind: RV},
+			},
+		},
+		{
+			Name: "B",
+			Availability: []Availability{
+				{Date: day("2021-02-20"), Kind: Walk},
+			},
+		},
+	}
+
+	got := GroupByWeekend(rs)
+	want := []Weekend{
+		{
+			Friday: day("2021-02-12"),
+			Results: []Result{
+				{Name: "A", Availability: []Availability{{Date: day("2021-02-12"), Kind: Tent}, {Date: day("2021-02-13"), Kind: RV}}},
+			},
+		},
+		{
+			Friday: day("2021-02-19"),
+			Results: []Result{
+				{Name: "A", Availability: []Availability{{Date: day("2021-02-19"), Kind: Tent}}},
+				{Name: "B", Availability: []Availability{{Date: day("2021-02-20"), Kind: Walk}}},
+			},
+		},
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("GroupByWeekend() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -1,6 +1,24 @@
 package campwiz
 
-import "time"
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// SortRating orders results by descending rating
+	SortRating = "rating"
+	// SortPrice orders results by ascending price per night, followed by those with unknown prices
+	SortPrice = "price"
+
+	// EquipmentTent is a tent, which fits most campsites
+	EquipmentTent = "tent"
+	// EquipmentRV is a motorhome or camper van
+	EquipmentRV = "rv"
+	// EquipmentTrailer is a towed trailer or fifth wheel
+	EquipmentTrailer = "trailer"
+)
 
 // Query defines a list of attributes that can be sent to the camp engines
 type Query struct {
@@ -12,6 +30,50 @@ type Query struct {
 	MinRating   float64
 	Keywords    []string
 
-	SiteKinds []int
+	// From and Until define a window of arrival dates, optionally limited to Weekdays
+	From     time.Time
+	Until    time.Time
+	Weekdays []time.Weekday
+
+	SiteKinds []SiteKind
 	Features  []int
+
+	// MaxPrice is the most to pay per night, or 0 for any price. Sites with unknown prices are not excluded.
+	MaxPrice float64
+	// SortBy is how to order results: SortRating (default) or SortPrice
+	SortBy string
+
+	// PartySize is the number of people camping, or 0 if unspecified
+	PartySize int
+	// Equipment is what will be camped in: EquipmentTent, EquipmentRV, EquipmentTrailer, or empty for any
+	Equipment string
+	// VehicleLength is the length in feet of the RV or trailer, or 0 if unspecified
+	VehicleLength int
+}
+
+// Vehicle returns true if the query is for an RV or trailer rather than a tent
+func (q Query) Vehicle() bool {
+	return q.Equipment == EquipmentRV || q.Equipment == EquipmentTrailer
+}
+
+// ParseSortBy checks a user-facing sort order, returning the default for an empty one
+func ParseSortBy(s string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", SortRating:
+		return SortRating, nil
+	case SortPrice:
+		return SortPrice, nil
+	default:
+		return "", fmt.Errorf("unknown sort order %q, choose from: %s, %s", s, SortRating, SortPrice)
+	}
+}
+
+// ParseEquipment checks a user-facing equipment type, returning an empty string for any
+func ParseEquipment(s string) (string, error) {
+	switch e := strings.ToLower(strings.TrimSpace(s)); e {
+	case "", EquipmentTent, EquipmentRV, EquipmentTrailer:
+		return e, nil
+	default:
+		return "", fmt.Errorf("unknown equipment %q, choose from: %s, %s, %s", s, EquipmentTent, EquipmentRV, EquipmentTrailer)
+	}
 }
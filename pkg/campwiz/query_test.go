@@ -0,0 +1,52 @@
+package campwiz
+
+import "testing"
+
+func TestParseEquipment(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{"", "", false},
+		{"tent", EquipmentTent, false},
+		{" RV", EquipmentRV, false},
+		{"Trailer", EquipmentTrailer, false},
+		{"hammock", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseEquipment(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseEquipment(%q) error = %v, want error: %v", tt.in, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseEquipment(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSiteKindFits(t *testing.T) {
+	tests := []struct {
+		kind      SiteKind
+		equipment string
+		want      bool
+	}{
+		{Day, "", true},
+		{Tent, EquipmentTent, true},
+		{Walk, EquipmentTent, true},
+		{Lodging, EquipmentTent, false},
+		{Tent, EquipmentRV, false},
+		{AccessibleRV, EquipmentRV, true},
+		{Standard, EquipmentTrailer, true},
+		{Walk, EquipmentTrailer, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.kind.Fits(tt.equipment); got != tt.want {
+			t.Errorf("%s.Fits(%q) = %v, want %v", tt.kind.Name(), tt.equipment, got, tt.want)
+		}
+	}
+}
@@ -15,6 +15,8 @@ type Campground struct {
 
 	ResURL string `yaml:"res_url,omitempty"`
 	ResID  string `yaml:"res_id,omitempty"`
+	// ResIDs maps a reservation site host to the ID it uses for this campground, such as reservecalifornia.com: "718"
+	ResIDs map[string]string `yaml:"res_ids,omitempty"`
 
 	Refs map[string]*Ref
 
@@ -51,6 +53,8 @@ type Source struct {
 	URL        string  `yaml:"url,omitempty"`
 	RatingMax  float64 `yaml:"rating_max,omitempty"`
 	RatingDesc string  `yaml:"rating_desc,omitempty"`
+	// Weight is how much this source counts towards a combined rating, relative to others (default 1)
+	Weight float64 `yaml:"weight,omitempty"`
 }
 
 type RefFile struct {
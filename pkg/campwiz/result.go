@@ -1,6 +1,8 @@
 package campwiz
 
 import (
+	"fmt"
+	"strings"
 	"time"
 )
 
@@ -12,11 +14,73 @@ type Availability struct {
 	Desc string
 
 	SpotCount int
+	// Sites are the individual sites available, if the provider lists them
+	Sites []Site
+
+	// PricePerNight is the lowest nightly fee, and TotalPrice the fee for the entire stay, or 0 if unknown
+	PricePerNight float64
+	TotalPrice    float64
 
 	Date time.Time
 	URL  string
 }
 
+// Site is an individual campsite
+type Site struct {
+	ID   string
+	Loop string
+
+	// MaxOccupancy is the most people allowed on the site, or 0 if unknown
+	MaxOccupancy int
+	// MaxVehicleLength is the longest vehicle allowed on the site in feet, or 0 if unknown
+	MaxVehicleLength int
+	// Hookups are the utilities provided, such as "electric", "water" and "sewer"
+	Hookups    []string
+	Accessible bool
+}
+
+// String returns the site ID, followed by any known details
+func (s Site) String() string {
+	details := []string{}
+	if s.Loop != "" {
+		details = append(details, s.Loop)
+	}
+	if s.MaxOccupancy > 0 {
+		details = append(details, fmt.Sprintf("%d people", s.MaxOccupancy))
+	}
+	if s.MaxVehicleLength > 0 {
+		details = append(details, fmt.Sprintf("%dft", s.MaxVehicleLength))
+	}
+	details = append(details, s.Hookups...)
+	if s.Accessible {
+		details = append(details, "accessible")
+	}
+
+	if len(details) == 0 {
+		return s.ID
+	}
+	return fmt.Sprintf("%s (%s)", s.ID, strings.Join(details, ", "))
+}
+
+// RatingScale is the maximum combined rating, which each source's rating is normalized to
+const RatingScale = 10.0
+
+// SourceRating is a single metadata source's contribution to a combined rating
+type SourceRating struct {
+	// Source is the key of the source in srcs.yaml
+	Source string
+	Name   string
+	Desc   string
+
+	Rating    float64
+	RatingMax float64
+	// Normalized is Rating scaled to be out of RatingScale
+	Normalized float64
+	Weight     float64
+
+	Lists []RefList
+}
+
 // Result is supposed to be a vendor neutral result of results
 type Result struct {
 	ResURL string
@@ -25,7 +89,13 @@ type Result struct {
 	Name     string
 	Distance float64
 
-	Rating float64
+	// Lat and Lon are the location of the campground, if known
+	Lat float64
+	Lon float64
+
+	// Rating is the weighted average of Ratings, out of RatingScale
+	Rating  float64
+	Ratings []SourceRating
 
 	Desc string
 	URL  string
@@ -37,4 +107,17 @@ type Result struct {
 	Locale       string
 
 	KnownCampground *Campground
+	// MatchConfidence is how sure we are that KnownCampground is correct, from 0 to 1
+	MatchConfidence float64
+}
+
+// LowestPrice returns the lowest known nightly fee across availability, or 0 if none are known
+func (r Result) LowestPrice() float64 {
+	lowest := 0.0
+	for _, a := range r.Availability {
+		if a.PricePerNight > 0 && (lowest == 0 || a.PricePerNight < lowest) {
+			lowest = a.PricePerNight
+		}
+	}
+	return lowest
 }
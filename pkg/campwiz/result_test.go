@@ -0,0 +1,22 @@
+package campwiz
+
+import "testing"
+
+func TestSiteString(t *testing.T) {
+	tests := []struct {
+		in   Site
+		want string
+	}{
+		{Site{ID: "6RV"}, "6RV"},
+		{Site{ID: "001", Loop: "Upper Pines", MaxOccupancy: 6}, "001 (Upper Pines, 6 people)"},
+		{Site{ID: "1E ADA", MaxVehicleLength: 36, Hookups: []string{"electric", "water"}, Accessible: true}, "1E ADA (36ft, electric, water, accessible)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := tt.in.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
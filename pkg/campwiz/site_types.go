@@ -1,5 +1,11 @@
 package campwiz
 
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
 type SiteKind string
 
 const (
@@ -31,3 +37,127 @@ const (
 	Beach                  = 4012
 	Winter                 = 4013
 )
+
+// SiteKindNames maps user-facing names to site kinds
+var SiteKindNames = map[string]SiteKind{
+	"rv":                  RV,
+	"accessible_rv":       AccessibleRV,
+	"standard":            Standard,
+	"accessible_standard": AccessibleStandard,
+	"lodging":             Lodging,
+	"tent":                Tent,
+	"group":               Group,
+	"day":                 Day,
+	"equestrian":          Equestrian,
+	"boat":                Boat,
+	"walk":                Walk,
+}
+
+// FeatureNames maps user-facing names to features
+var FeatureNames = map[string]int{
+	"biking":        Biking,
+	"boating":       Boating,
+	"rental":        EquipmentRental,
+	"fishing":       Fishing,
+	"golf":          Golf,
+	"hiking":        Hiking,
+	"horseback":     HorsebackRiding,
+	"hunting":       Hunting,
+	"recreation":    RecreationalActivities,
+	"scenic_trails": ScenicTrails,
+	"sports":        Sports,
+	"beach":         Beach,
+	"winter":        Winter,
+}
+
+// FeatureKeywords are the case-insensitive substrings that show a campground has a feature
+var FeatureKeywords = map[int][]string{
+	Biking:                 {"bik", "bicycl"},
+	Boating:                {"boat", "kayak", "canoe", "paddl"},
+	EquipmentRental:        {"rental"},
+	Fishing:                {"fish"},
+	Golf:                   {"golf"},
+	Hiking:                 {"hik", "trail"},
+	HorsebackRiding:        {"horse", "equestrian"},
+	Hunting:                {"hunt"},
+	RecreationalActivities: {"recreation"},
+	ScenicTrails:           {"scenic", "vista"},
+	Sports:                 {"sport", "ball", "tennis"},
+	Beach:                  {"beach", "swim", "surf"},
+	Winter:                 {"winter", "ski", "snow"},
+}
+
+// Name returns the user-facing name of a site kind
+func (k SiteKind) Name() string {
+	for n, v := range SiteKindNames {
+		if v == k {
+			return n
+		}
+	}
+	return ""
+}
+
+// Accessible returns true if the site kind is accessible
+func (k SiteKind) Accessible() bool {
+	return k == AccessibleRV || k == AccessibleStandard
+}
+
+// Fits returns true if equipment, such as EquipmentRV, may be used on the site kind. Any kind fits empty equipment.
+func (k SiteKind) Fits(equipment string) bool {
+	switch equipment {
+	case "":
+		return true
+	case EquipmentTent:
+		return k != Day && k != Lodging
+	case EquipmentRV, EquipmentTrailer:
+		return k == RV || k == AccessibleRV || k == Standard || k == AccessibleStandard
+	default:
+		return false
+	}
+}
+
+// SiteKindList returns the sorted names of all site kinds
+func SiteKindList() []string {
+	ns := []string{}
+	for k := range SiteKindNames {
+		ns = append(ns, k)
+	}
+	sort.Strings(ns)
+	return ns
+}
+
+// FeatureList returns the sorted names of all features
+func FeatureList() []string {
+	ns := []string{}
+	for k := range FeatureNames {
+		ns = append(ns, k)
+	}
+	sort.Strings(ns)
+	return ns
+}
+
+// ParseSiteKinds converts user-facing names to site kinds
+func ParseSiteKinds(names []string) ([]SiteKind, error) {
+	ks := []SiteKind{}
+	for _, n := range names {
+		k, ok := SiteKindNames[strings.ToLower(strings.TrimSpace(n))]
+		if !ok {
+			return nil, fmt.Errorf("unknown site kind %q, choose from: %s", n, strings.Join(SiteKindList(), ", "))
+		}
+		ks = append(ks, k)
+	}
+	return ks, nil
+}
+
+// ParseFeatures converts user-facing names to features
+func ParseFeatures(names []string) ([]int, error) {
+	fs := []int{}
+	for _, n := range names {
+		f, ok := FeatureNames[strings.ToLower(strings.TrimSpace(n))]
+		if !ok {
+			return nil, fmt.Errorf("unknown feature %q, choose from: %s", n, strings.Join(FeatureList(), ", "))
+		}
+		fs = append(fs, f)
+	}
+	return fs, nil
+}
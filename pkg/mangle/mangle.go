@@ -3,6 +3,7 @@ package mangle
 
 import (
 	"regexp"
+	"strconv"
 	"strings"
 	"unicode"
 
@@ -65,6 +66,8 @@ var (
 	nonWordRe = regexp.MustCompile(`\W+`)
 	// extra space
 	spaceRe = regexp.MustCompile(`\s+`)
+	// dollar amounts, such as "$1,045.50"
+	priceRe = regexp.MustCompile(`\$\s*([0-9][0-9,]*(?:\.[0-9]+)?)`)
 
 	normCache = map[string]string{}
 )
@@ -226,3 +229,18 @@ func Features(s string) []string {
 	}
 	return hs
 }
+
+// Price returns the lowest dollar amount within a raw string, such as "$35.00 - $45.00 per night", or 0 if there is none
+func Price(s string) float64 {
+	lowest := 0.0
+	for _, m := range priceRe.FindAllStringSubmatch(s, -1) {
+		p, err := strconv.ParseFloat(strings.ReplaceAll(m[1], ",", ""), 64)
+		if err != nil || p <= 0 {
+			continue
+		}
+		if lowest == 0 || p < lowest {
+			lowest = p
+		}
+	}
+	return lowest
+}
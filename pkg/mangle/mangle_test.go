@@ -120,3 +120,25 @@ func TestTitle(t *testing.T) {
 		})
 	}
 }
+
+func TestPrice(t *testing.T) {
+	tests := []struct {
+		in  string
+		out float64
+	}{
+		{"$45.00", 45},
+		{"$35.00 - $45.00 per night", 35},
+		{"Group site: $1,045.50", 1045.5},
+		{"Free", 0},
+		{"", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got := Price(tt.in)
+			if got != tt.out {
+				t.Errorf("got %.2f, want %.2f", got, tt.out)
+			}
+		})
+	}
+}
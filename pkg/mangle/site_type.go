@@ -45,3 +45,44 @@ func SiteKind(title string, kind string, sid string) campwiz.SiteKind {
 
 	return campwiz.Standard
 }
+
+// Hookups returns the utilities mentioned by site descriptions, such as "Camping - RV/Electric"
+func Hookups(descs ...string) []string {
+	found := map[string]bool{}
+	for _, d := range descs {
+		d = nonWordRe.ReplaceAllString(strings.ToLower(d), " ")
+		prev := ""
+		for _, w := range strings.Split(d, " ") {
+			// "Non-Electric" and "No Water" are the lack of a hookup
+			if prev == "non" || prev == "no" {
+				prev = w
+				continue
+			}
+			prev = w
+
+			switch w {
+			case "electric", "electrical":
+				found["electric"] = true
+			case "water":
+				found["water"] = true
+			case "sewer":
+				found["sewer"] = true
+			case "full":
+				if strings.Contains(d, "full hookup") {
+					found["electric"], found["water"], found["sewer"] = true, true, true
+				}
+			}
+		}
+	}
+
+	hs := []string{}
+	for _, h := range []string{"electric", "water", "sewer"} {
+		if found[h] {
+			hs = append(hs, h)
+		}
+	}
+	if len(hs) == 0 {
+		return nil
+	}
+	return hs
+}
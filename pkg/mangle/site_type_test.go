@@ -1,8 +1,10 @@
 package mangle
 
 import (
+	"strings"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/tstromberg/campwiz/pkg/campwiz"
 )
 
@@ -11,10 +13,11 @@ func TestSiteKind(t *testing.T) {
 		title string
 		kind  string
 		sid   string
-		out   campwiz.Kind
+		out   campwiz.SiteKind
 	}{
 		{"Frank Valley Horse Camp", "", "", campwiz.Equestrian},
-		{"Shasta-Trinity Park", "", "", campwiz.Tent},
+		// Names without a site type keyword fall back to Standard
+		{"Shasta-Trinity Park", "", "", campwiz.Standard},
 		{"Shasta-Trinity Boat-In", "", "", campwiz.Boat},
 		{"Angel Island Group Campsite", "", "", campwiz.Group},
 		{"Joseph Grant Park", "Camping - Tent/Non-Electric", "#8-Horse Camp Only *", campwiz.Equestrian},
@@ -32,3 +35,25 @@ func TestSiteKind(t *testing.T) {
 		})
 	}
 }
+
+func TestHookups(t *testing.T) {
+	tests := []struct {
+		in  []string
+		out []string
+	}{
+		{[]string{"Camping - RV/Electric"}, []string{"electric"}},
+		{[]string{"Camping - Tent/Non-Electric"}, nil},
+		{[]string{"STANDARD NONELECTRIC"}, nil},
+		{[]string{"RV Full Hookup"}, []string{"electric", "water", "sewer"}},
+		{[]string{"Cabin, no water", "Electric"}, []string{"electric"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.in, ","), func(t *testing.T) {
+			got := Hookups(tt.in...)
+			if diff := cmp.Diff(tt.out, got); diff != "" {
+				t.Errorf("Hookups(%v) mismatch (-want +got):\n%s", tt.in, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,256 @@
+package metadata
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/tstromberg/campwiz/pkg/campwiz"
+	"gopkg.in/yaml.v3"
+)
+
+// idRe is the expected form of a property ID: /<state>/<area>/<name>, where single-area properties may omit the name
+var idRe = regexp.MustCompile(`^/[a-z]{2}/[a-z0-9_]+(/[a-z0-9_]+)?$`)
+
+// Problem is an issue found within a metadata file
+type Problem struct {
+	Path string
+	Line int
+	Msg  string
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("%s:%d: %s", p.Path, p.Line, p.Msg)
+}
+
+// location is where something was defined
+type location struct {
+	path string
+	line int
+}
+
+// linter accumulates state across the metadata files being checked
+type linter struct {
+	problems []Problem
+	srcs     map[string]campwiz.Source
+	// propIDs are the property IDs defined within the current file
+	propIDs map[string]location
+
+	// refs are checked against sources once every file has been read, as they may be defined anywhere
+	refs []lintRef
+}
+
+type lintRef struct {
+	loc    location
+	source string
+	rating float64
+}
+
+func (l *linter) add(path string, line int, format string, args ...interface{}) {
+	l.problems = append(l.problems, Problem{Path: path, Line: line, Msg: fmt.Sprintf(format, args...)})
+}
+
+// Lint checks metadata files, or directories of them, for problems, returning every one that was found
+func Lint(paths []string) ([]Problem, error) {
+	l := &linter{srcs: map[string]campwiz.Source{}}
+
+	files, err := Files(paths)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, path := range files {
+		// Later files may override properties from earlier ones, but not redefine them within the same file
+		l.propIDs = map[string]location{}
+		bs, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		l.file(path, bs)
+	}
+
+	for _, r := range l.refs {
+		src, ok := l.srcs[r.source]
+		if !ok {
+			l.add(r.loc.path, r.loc.line, "ref source %q is not defined in any sources", r.source)
+			continue
+		}
+		if r.rating < 0 || (src.RatingMax > 0 && r.rating > src.RatingMax) {
+			l.add(r.loc.path, r.loc.line, "rating %.1f is outside of 0-%.0f for %q", r.rating, src.RatingMax, r.source)
+		}
+	}
+
+	sort.SliceStable(l.problems, func(i, j int) bool {
+		if l.problems[i].Path != l.problems[j].Path {
+			return l.problems[i].Path < l.problems[j].Path
+		}
+		return l.problems[i].Line < l.problems[j].Line
+	})
+	return l.problems, nil
+}
+
+// file checks a single metadata file
+func (l *linter) file(path string, bs []byte) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(bs, &doc); err != nil {
+		l.add(path, 0, "unparseable: %v", err)
+		return
+	}
+	if len(doc.Content) == 0 {
+		return
+	}
+	root := doc.Content[0]
+
+	if n := value(root, "sources"); n != nil {
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key, sn := n.Content[i], n.Content[i+1]
+			var src campwiz.Source
+			if err := sn.Decode(&src); err != nil {
+				l.add(path, sn.Line, "source %q: %v", key.Value, err)
+				continue
+			}
+			if src.RatingMax < 0 {
+				l.add(path, line(sn, "rating_max"), "source %q has a negative rating_max", key.Value)
+			}
+			l.url(path, sn, "url", src.URL)
+			l.srcs[key.Value] = src
+		}
+	}
+
+	if n := value(root, "properties"); n != nil {
+		for _, pn := range n.Content {
+			l.property(path, pn)
+		}
+	}
+}
+
+// property checks a single property definition
+func (l *linter) property(path string, pn *yaml.Node) {
+	var p campwiz.Property
+	if err := pn.Decode(&p); err != nil {
+		l.add(path, pn.Line, "property: %v", err)
+		return
+	}
+
+	idLine := line(pn, "id")
+	switch {
+	case p.ID == "":
+		l.add(path, pn.Line, "property %q has no id", p.Name)
+	case !idRe.MatchString(p.ID):
+		l.add(path, idLine, "property id %q does not match /<state>/<area>/<name>", p.ID)
+	}
+
+	if p.ID != "" {
+		if prev, ok := l.propIDs[p.ID]; ok {
+			l.add(path, idLine, "duplicate property id %q, first defined at %s:%d", p.ID, prev.path, prev.line)
+		} else {
+			l.propIDs[p.ID] = location{path: path, line: idLine}
+		}
+	}
+
+	l.url(path, pn, "url", p.URL)
+
+	cgs := value(pn, "campgrounds")
+	if cgs == nil {
+		l.add(path, pn.Line, "property %q has no campgrounds", p.ID)
+		return
+	}
+
+	seen := map[string]bool{}
+	for i, cn := range cgs.Content {
+		if i >= len(p.Campgrounds) {
+			break
+		}
+		cg := p.Campgrounds[i]
+
+		if cg.ID == "" {
+			l.add(path, cn.Line, "campground %q has no id", cg.Name)
+		} else if seen[cg.ID] {
+			l.add(path, line(cn, "id"), "duplicate campground id %q within %q", cg.ID, p.ID)
+		}
+		seen[cg.ID] = true
+
+		l.url(path, cn, "url", cg.URL)
+		l.resURL(path, cn, cg.ResURL)
+
+		refs := value(cn, "refs")
+		if refs == nil {
+			continue
+		}
+		for i := 0; i+1 < len(refs.Content); i += 2 {
+			key, rn := refs.Content[i], refs.Content[i+1]
+			l.ref(path, key, rn, cg.Refs[key.Value])
+		}
+	}
+}
+
+// ref checks a single cross-reference entry
+func (l *linter) ref(path string, key *yaml.Node, rn *yaml.Node, ref *campwiz.Ref) {
+	if ref == nil {
+		return
+	}
+
+	l.refs = append(l.refs, lintRef{loc: location{path: path, line: line(rn, "rating")}, source: key.Value, rating: ref.Rating})
+	l.url(path, rn, "url", ref.URL)
+	l.url(path, rn, "image_url", ref.ImageURL)
+
+	// Descriptions are compressed, unless they link to their source
+	if ref.Desc != "" && ref.URL == "" {
+		if _, err := Decode(ref.Desc); err != nil {
+			l.add(path, line(rn, "desc"), "undecodable desc: %v", err)
+		}
+	}
+}
+
+// url checks that an optional URL field is well-formed
+func (l *linter) url(path string, n *yaml.Node, key string, s string) {
+	if s == "" {
+		return
+	}
+
+	u, err := url.Parse(s)
+	if err != nil {
+		l.add(path, line(n, key), "%s %q: %v", key, s, err)
+		return
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		l.add(path, line(n, key), "%s %q is not an absolute http(s) URL", key, s)
+	}
+}
+
+// resURL checks a reservation URL, which may also be a mailto: address for campgrounds reserved by e-mail
+func (l *linter) resURL(path string, n *yaml.Node, s string) {
+	if !strings.HasPrefix(s, "mailto:") {
+		l.url(path, n, "res_url", s)
+		return
+	}
+
+	u, err := url.Parse(s)
+	if err != nil || !strings.Contains(u.Opaque, "@") {
+		l.add(path, line(n, "res_url"), "res_url %q is not a valid mailto: address", s)
+	}
+}
+
+// value returns the value node for a key within a mapping node
+func value(n *yaml.Node, key string) *yaml.Node {
+	if n == nil || n.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// line returns the line a key is defined on within a mapping node, or the line of the node itself
+func line(n *yaml.Node, key string) int {
+	if v := value(n, key); v != nil {
+		return v.Line
+	}
+	return n.Line
+}
@@ -0,0 +1,52 @@
+package metadata
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLint(t *testing.T) {
+	srcs := "testdata/lint_srcs.yaml"
+	props := "testdata/lint_props.yaml"
+
+	got, err := Lint([]string{srcs, props})
+	if err != nil {
+		t.Fatalf("Lint: %v", err)
+	}
+
+	want := []Problem{
+		{Path: props, Line: 8, Msg: `res_url "www.recreation.gov" is not an absolute http(s) URL`},
+		{Path: props, Line: 13, Msg: `rating 12.0 is outside of 0-10 for "cc"`},
+		{Path: props, Line: 16, Msg: `ref source "yelp" is not defined in any sources`},
+		{Path: props, Line: 17, Msg: `duplicate property id "/ca/chico/zlky", first defined at testdata/lint_props.yaml:2`},
+		{Path: props, Line: 24, Msg: `undecodable desc: missing "z" prefix`},
+		{Path: props, Line: 25, Msg: `property id "Campwiz National Forest" does not match /<state>/<area>/<name>`},
+		{Path: props, Line: 30, Msg: `duplicate campground id "campy" within "Campwiz National Forest"`},
+		{Path: props, Line: 37, Msg: `res_url "mailto:reservations" is not a valid mailto: address`},
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Lint() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	want := "this is a test desc"
+	got, err := Decode(Compress(want))
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if got != want {
+		t.Errorf("Decode() = %q, want %q", got, want)
+	}
+
+	for _, bad := range []string{"", "not compressed", "z!!!", "zwATAOz"} {
+		if got, err := Decode(bad); err == nil {
+			t.Errorf("Decode(%q) = %q, expected error", bad, got)
+		}
+		if got := Decompress(bad); got != "" {
+			t.Errorf("Decompress(%q) = %q, expected empty string", bad, got)
+		}
+	}
+}
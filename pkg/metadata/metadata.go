@@ -5,10 +5,16 @@ import (
 	"compress/gzip"
 	"encoding/base64"
 	"fmt"
+	"io/fs"
 	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
 	"strings"
 
+	bundled "github.com/tstromberg/campwiz/metadata"
 	"github.com/tstromberg/campwiz/pkg/campwiz"
+	"github.com/tstromberg/campwiz/pkg/mangle"
 	"github.com/tstromberg/campwiz/pkg/relpath"
 	"k8s.io/klog/v2"
 
@@ -20,76 +26,226 @@ var (
 	CompressPrefix = `z`
 )
 
-// LoadAll returns all cross-reference data
+// DefaultDir is where metadata lives within the source tree
+const DefaultDir = "metadata"
+
+// LoadAll returns all cross-reference data from the source tree, or the bundled copy if it can't be found
 func LoadAll() (map[string]campwiz.Source, map[string]*campwiz.Property, error) {
-	csrcs := map[string]campwiz.Source{}
-	cprops := map[string]*campwiz.Property{}
+	return Load(nil)
+}
 
-	for _, p := range []string{"metadata/srcs.yaml", "metadata/ca.yaml"} {
-		path := relpath.Find(p)
-		if path == "" {
-			klog.Errorf("unable to find %s", p)
+// Load returns cross-reference data merged from YAML files or directories of them, in order.
+// Without any paths, the source tree is used if it can be found, falling back to the bundled copy.
+func Load(paths []string) (map[string]campwiz.Source, map[string]*campwiz.Property, error) {
+	paths = Resolve(paths)
+	if len(paths) == 0 {
+		klog.Infof("%s not found, using bundled metadata", DefaultDir)
+		return LoadFS(bundled.FS)
+	}
+
+	files, err := Files(paths)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	m := newMerger()
+	for _, f := range files {
+		bs, err := ioutil.ReadFile(f)
+		if err != nil {
+			return nil, nil, err
 		}
-		klog.Infof("%s is at %s", p, path)
+		if err := m.add(f, bs); err != nil {
+			return nil, nil, err
+		}
+	}
+	return m.srcs, m.props, nil
+}
+
+// Resolve returns the paths that Load reads from: the source tree if none are given, or none if the bundled copy is to be used
+func Resolve(paths []string) []string {
+	if len(paths) > 0 {
+		return paths
+	}
+
+	dir := relpath.Find(DefaultDir)
+	if fi, err := os.Stat(dir); err != nil || !fi.IsDir() {
+		return nil
+	}
+	return []string{dir}
+}
 
-		srcs, props, err := loadPath(path)
+// LoadFS returns cross-reference data merged from the YAML files within a filesystem, in name order
+func LoadFS(fsys fs.FS) (map[string]campwiz.Source, map[string]*campwiz.Property, error) {
+	files, err := fs.Glob(fsys, "*.yaml")
+	if err != nil {
+		return nil, nil, err
+	}
+	sort.Strings(files)
+
+	m := newMerger()
+	for _, f := range files {
+		bs, err := fs.ReadFile(fsys, f)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := m.add(f, bs); err != nil {
+			return nil, nil, err
+		}
+	}
+	return m.srcs, m.props, nil
+}
+
+// Files expands a list of paths into the YAML files they refer to: directories are replaced by their YAML files, in name order
+func Files(paths []string) ([]string, error) {
+	files := []string{}
+	for _, p := range paths {
+		fi, err := os.Stat(p)
 		if err != nil {
-			return srcs, props, fmt.Errorf("loadpath %q: %v", path, err)
+			return nil, err
 		}
 
-		for k, v := range srcs {
-			csrcs[k] = v
+		if !fi.IsDir() {
+			files = append(files, p)
+			continue
 		}
-		for k, v := range props {
-			cprops[k] = v
+
+		matches, err := filepath.Glob(filepath.Join(p, "*.yaml"))
+		if err != nil {
+			return nil, err
 		}
+		sort.Strings(matches)
+		files = append(files, matches...)
 	}
+	return files, nil
+}
 
-	return csrcs, cprops, nil
+// merger combines metadata files, with later files overriding or extending earlier ones
+type merger struct {
+	srcs  map[string]campwiz.Source
+	props map[string]*campwiz.Property
 }
 
-// LoadPath loads YAML data from a Path!
-// LoadCC returns CC cross-reference data
-func loadPath(path string) (map[string]campwiz.Source, map[string]*campwiz.Property, error) {
-	p := relpath.Find(path)
-	f, err := ioutil.ReadFile(p)
-	if err != nil {
-		return nil, nil, err
+func newMerger() *merger {
+	return &merger{srcs: map[string]campwiz.Source{}, props: map[string]*campwiz.Property{}}
+}
+
+// add merges a metadata file into what has been loaded so far
+func (m *merger) add(name string, bs []byte) error {
+	var rf campwiz.RefFile
+	if err := yaml.Unmarshal(bs, &rf); err != nil {
+		return fmt.Errorf("parse %s: %w", name, err)
 	}
+	klog.V(1).Infof("Loaded %d sources and %d properties from %s ...", len(rf.Sources), len(rf.Properties), name)
 
-	var ccd campwiz.RefFile
-	err = yaml.Unmarshal(f, &ccd)
-	if err != nil {
-		return nil, nil, err
+	for k, v := range rf.Sources {
+		m.srcs[k] = v
 	}
 
-	klog.V(1).Infof("Loaded %d entries from %s ...", len(ccd.Properties), p)
+	for _, p := range rf.Properties {
+		if prev, ok := m.props[p.ID]; ok {
+			klog.V(1).Infof("%s overrides property %q", name, p.ID)
+			mergeProperty(prev, p)
+		} else {
+			m.props[p.ID] = p
+		}
 
-	props := map[string]*campwiz.Property{}
-	for _, p := range ccd.Properties {
-		props[p.ID] = p
+		for _, cg := range m.props[p.ID].Campgrounds {
+			cg.PropertyID = p.ID
+		}
 	}
-	return ccd.Sources, props, nil
+	return nil
 }
 
+// mergeProperty overrides the fields of a property which are set in an update, merging campgrounds by ID
+func mergeProperty(p *campwiz.Property, update *campwiz.Property) {
+	if update.URL != "" {
+		p.URL = update.URL
+	}
+	if update.Name != "" {
+		p.Name = update.Name
+	}
+	if update.ManagedBy != "" {
+		p.ManagedBy = update.ManagedBy
+	}
+
+	for _, ucg := range update.Campgrounds {
+		var cg *campwiz.Campground
+		for _, c := range p.Campgrounds {
+			if c.ID == ucg.ID {
+				cg = c
+			}
+		}
+
+		if cg == nil {
+			p.Campgrounds = append(p.Campgrounds, ucg)
+			continue
+		}
+		mergeCampground(cg, ucg)
+	}
+}
+
+// mergeCampground overrides the fields of a campground which are set in an update, replacing refs by source
+func mergeCampground(cg *campwiz.Campground, update *campwiz.Campground) {
+	if update.Name != "" {
+		cg.Name = update.Name
+	}
+	if update.URL != "" {
+		cg.URL = update.URL
+	}
+	if update.ResURL != "" {
+		cg.ResURL = update.ResURL
+	}
+	if update.ResID != "" {
+		cg.ResID = update.ResID
+	}
+
+	if len(update.ResIDs) > 0 && cg.ResIDs == nil {
+		cg.ResIDs = map[string]string{}
+	}
+	for k, v := range update.ResIDs {
+		cg.ResIDs[k] = v
+	}
+
+	if len(update.Refs) > 0 && cg.Refs == nil {
+		cg.Refs = map[string]*campwiz.Ref{}
+	}
+	for k, v := range update.Refs {
+		cg.Refs[k] = v
+	}
+}
+
+// Decompress returns the original form of a compressed description, or an empty string if it is corrupt
 func Decompress(s string) string {
+	d, err := Decode(s)
+	if err != nil {
+		klog.Errorf("decompress %q: %v", mangle.Ellipsis(s, 16), err)
+		return ""
+	}
+	return d
+}
+
+// Decode returns the original form of a compressed description
+func Decode(s string) (string, error) {
+	if !strings.HasPrefix(s, CompressPrefix) {
+		return "", fmt.Errorf("missing %q prefix", CompressPrefix)
+	}
+
 	bs, err := base64.RawStdEncoding.DecodeString(CompressHeader + s[1:])
 	if err != nil {
-		klog.Fatalf("decode fail: %v", err)
+		return "", fmt.Errorf("decode: %w", err)
 	}
 
-	buf := bytes.NewReader(bs)
-	zr, err := gzip.NewReader(buf)
+	zr, err := gzip.NewReader(bytes.NewReader(bs))
 	if err != nil {
-		klog.Fatalf("reader fail: %v", err)
+		return "", fmt.Errorf("reader: %w", err)
 	}
 
 	d, err := ioutil.ReadAll(zr)
 	if err != nil {
-		klog.Fatalf("read fail: %v", err)
+		return "", fmt.Errorf("read: %w", err)
 	}
 
-	return string(d)
+	return string(d), nil
 }
 
 func Compress(s string) string {
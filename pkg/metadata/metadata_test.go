@@ -0,0 +1,78 @@
+package metadata
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	bundled "github.com/tstromberg/campwiz/metadata"
+	"github.com/tstromberg/campwiz/pkg/campwiz"
+)
+
+func TestLoad(t *testing.T) {
+	srcs, props, err := Load([]string{"testdata/base", "testdata/override/private.yaml"})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	wantSrcs := map[string]campwiz.Source{
+		"cc":   {Name: "California Camping", RatingMax: 10},
+		"team": {Name: "Our Team", RatingMax: 5},
+	}
+	if diff := cmp.Diff(wantSrcs, srcs); diff != "" {
+		t.Errorf("Load() sources mismatch (-want +got):\n%s", diff)
+	}
+
+	want := map[string]*campwiz.Property{
+		"/ca/chico/zlky": {
+			ID:   "/ca/chico/zlky",
+			URL:  "http://www.fs.usda.gov/elsewhere",
+			Name: "Zlky Renamed",
+			Campgrounds: []*campwiz.Campground{
+				{
+					ID:     "default",
+					Name:   "Mt. Elky",
+					ResIDs: map[string]string{"recreation.gov": "1234"},
+					Refs: map[string]*campwiz.Ref{
+						"cc":   {Name: "Elky", Rating: 2},
+						"team": {Rating: 4},
+					},
+					PropertyID: "/ca/chico/zlky",
+				},
+				{ID: "overflow", Name: "Elky Overflow", PropertyID: "/ca/chico/zlky"},
+			},
+		},
+		"/ca/sj/grant": {
+			ID:          "/ca/sj/grant",
+			Name:        "Joseph D. Grant County Park",
+			Campgrounds: []*campwiz.Campground{{ID: "grant", Name: "Joseph D. Grant County Park", PropertyID: "/ca/sj/grant"}},
+		},
+		"/or/bend/tumalo": {
+			ID:          "/or/bend/tumalo",
+			Name:        "Tumalo State Park",
+			Campgrounds: []*campwiz.Campground{{ID: "tumalo", Name: "Tumalo State Park", PropertyID: "/or/bend/tumalo"}},
+		},
+	}
+	if diff := cmp.Diff(want, props); diff != "" {
+		t.Errorf("Load() properties mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestLoadMissing(t *testing.T) {
+	if _, _, err := Load([]string{"testdata/missing"}); err == nil {
+		t.Errorf("Load() of a missing path returned no error")
+	}
+}
+
+func TestLoadFSBundled(t *testing.T) {
+	srcs, props, err := LoadFS(bundled.FS)
+	if err != nil {
+		t.Fatalf("LoadFS: %v", err)
+	}
+
+	if _, ok := srcs["cc"]; !ok {
+		t.Errorf("bundled sources are missing cc: %v", srcs)
+	}
+	if len(props) < 100 {
+		t.Errorf("got %d bundled properties, expected many more", len(props))
+	}
+}
@@ -0,0 +1,49 @@
+package render
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// csvHeader lists the columns written by CSV
+var csvHeader = []string{"name", "distance", "rating", "date", "kind", "spot_count", "site", "desc", "url", "res_url", "res_id", "locale"}
+
+// CSV writes search results as CSV, with one row per availability entry
+func CSV(w io.Writer, c Context) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, r := range NewDocument(c).Results {
+		for _, a := range r.Availability {
+			url := a.URL
+			if url == "" {
+				url = r.URL
+			}
+
+			row := []string{
+				r.Name,
+				fmt.Sprintf("%.1f", r.Distance),
+				fmt.Sprintf("%.1f", r.Rating),
+				a.Date,
+				a.Kind,
+				strconv.Itoa(a.SpotCount),
+				a.Name,
+				a.Desc,
+				url,
+				r.ResURL,
+				r.ResID,
+				r.Locale,
+			}
+			if err := cw.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
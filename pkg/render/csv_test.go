@@ -0,0 +1,22 @@
+package render
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := CSV(&buf, testContext()); err != nil {
+		t.Fatalf("CSV: %v", err)
+	}
+
+	want := `name,distance,rating,date,kind,spot_count,site,desc,url,res_url,res_id,locale
+Portola Redwoods SP,6.0,7.0,2021-02-12,⛺,12,Portola Campground,Tent Campsite,,https://www.reservecalifornia.com/,695,
+`
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("CSV() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,101 @@
+package render
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/tstromberg/campwiz/pkg/campwiz"
+)
+
+// now returns the current time, overridden in tests
+var now = time.Now
+
+// icalEscape escapes text values as described in RFC 5545, section 3.3.11
+func icalEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
+
+// icalFold splits content lines longer than 75 octets, without breaking multi-byte characters
+func icalFold(line string) string {
+	var b strings.Builder
+	n := 0
+	for _, r := range line {
+		size := len(string(r))
+		if n+size > 75 {
+			b.WriteString("\r\n ")
+			n = 1
+		}
+		b.WriteRune(r)
+		n += size
+	}
+	b.WriteString("\r\n")
+	return b.String()
+}
+
+// icalUID returns a stable identifier for an availability entry, so that calendar subscriptions update in place
+func icalUID(r campwiz.Result, a campwiz.Availability) string {
+	h := sha1.Sum([]byte(strings.Join([]string{r.ResURL, r.ResID, r.Name, a.Date.Format(dateFormat), string(a.Kind), a.Name, a.Desc}, "|")))
+	return fmt.Sprintf("%x@campwiz", h)
+}
+
+// ICal writes search results as an iCalendar file, with an all-day event spanning the stay for each availability entry
+func ICal(w io.Writer, c Context) error {
+	nights := c.Query.StayLength
+	if nights < 1 {
+		nights = 1
+	}
+	stamp := now().UTC().Format("20060102T150405Z")
+
+	var b strings.Builder
+	line := func(s string) { b.WriteString(icalFold(s)) }
+
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//campwiz//campwiz//EN")
+	line("CALSCALE:GREGORIAN")
+	line("X-WR-CALNAME:campwiz")
+
+	for _, r := range c.Results {
+		for _, a := range r.Availability {
+			url := a.URL
+			if url == "" {
+				url = r.URL
+			}
+
+			desc := []string{fmt.Sprintf("%d available %s sites", a.SpotCount, a.Kind)}
+			if a.Name != "" && a.Name != r.Name {
+				desc = append(desc, a.Name)
+			}
+			if a.Desc != "" {
+				desc = append(desc, a.Desc)
+			}
+			if url != "" {
+				desc = append(desc, "Reserve: "+url)
+			}
+
+			line("BEGIN:VEVENT")
+			line("UID:" + icalUID(r, a))
+			line("DTSTAMP:" + stamp)
+			line("DTSTART;VALUE=DATE:" + a.Date.Format("20060102"))
+			line("DTEND;VALUE=DATE:" + a.Date.AddDate(0, 0, nights).Format("20060102"))
+			line("SUMMARY:" + icalEscape(fmt.Sprintf("%s: %dx%s", r.Name, a.SpotCount, a.Kind)))
+			line("DESCRIPTION:" + icalEscape(strings.Join(desc, "\n")))
+			if r.Locale != "" {
+				line("LOCATION:" + icalEscape(r.Locale))
+			}
+			if url != "" {
+				line("URL:" + url)
+			}
+			line("TRANSP:TRANSPARENT")
+			line("END:VEVENT")
+		}
+	}
+
+	line("END:VCALENDAR")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
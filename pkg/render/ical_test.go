@@ -0,0 +1,62 @@
+package render
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestICal(t *testing.T) {
+	orig := now
+	defer func() { now = orig }()
+	now = func() time.Time { return time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC) }
+
+	c := testContext()
+	c.Results[0].Locale = "Santa Cruz Mountains, near La Honda"
+	c.Results[0].Availability[0].URL = "https://www.reservecalifornia.com/CaliforniaWebHome/Facilities/SearchViewUnitAvailabity.aspx"
+
+	var buf bytes.Buffer
+	if err := ICal(&buf, c); err != nil {
+		t.Fatalf("ICal: %v", err)
+	}
+
+	want := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//campwiz//campwiz//EN",
+		"CALSCALE:GREGORIAN",
+		"X-WR-CALNAME:campwiz",
+		"BEGIN:VEVENT",
+		"UID:" + icalUID(c.Results[0], c.Results[0].Availability[0]),
+		"DTSTAMP:20210102T030405Z",
+		"DTSTART;VALUE=DATE:20210212",
+		"DTEND;VALUE=DATE:20210214",
+		"SUMMARY:Portola Redwoods SP: 12x⛺",
+		`DESCRIPTION:12 available ⛺ sites\nPortola Campground\nTent Campsite\nRese`,
+		" rve: https://www.reservecalifornia.com/CaliforniaWebHome/Facilities/Search",
+		" ViewUnitAvailabity.aspx",
+		`LOCATION:Santa Cruz Mountains\, near La Honda`,
+		"URL:https://www.reservecalifornia.com/CaliforniaWebHome/Facilities/SearchVi",
+		" ewUnitAvailabity.aspx",
+		"TRANSP:TRANSPARENT",
+		"END:VEVENT",
+		"END:VCALENDAR",
+		"",
+	}, "\r\n")
+
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("ICal() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestICalFold(t *testing.T) {
+	long := strings.Repeat("⛺", 40)
+	for _, l := range strings.Split(strings.TrimSuffix(icalFold("SUMMARY:"+long), "\r\n"), "\r\n") {
+		if len(l) > 75 {
+			t.Errorf("line is %d octets: %q", len(l), l)
+		}
+	}
+}
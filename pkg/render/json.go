@@ -0,0 +1,283 @@
+// Package render outputs search results in machine-readable formats
+package render
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/tstromberg/campwiz/pkg/campwiz"
+)
+
+const dateFormat = "2006-01-02"
+
+// SchemaVersion is incremented whenever the JSON representation changes incompatibly
+const SchemaVersion = 1
+
+// Context is everything needed to render a set of search results
+type Context struct {
+	Query   campwiz.Query
+	Sources map[string]campwiz.Source
+	Results []campwiz.Result
+	Errors  []error
+}
+
+// Document is the stable JSON representation of a search
+type Document struct {
+	Version int               `json:"version"`
+	Query   Query             `json:"query"`
+	Sources map[string]Source `json:"sources"`
+	Results []Result          `json:"results"`
+	Errors  []string          `json:"errors"`
+}
+
+// Query is the JSON representation of a search query
+type Query struct {
+	Lat         float64  `json:"lat"`
+	Lon         float64  `json:"lon"`
+	Dates       []string `json:"dates"`
+	From        string   `json:"from,omitempty"`
+	Until       string   `json:"until,omitempty"`
+	Weekdays    []string `json:"weekdays,omitempty"`
+	StayLength  int      `json:"nights"`
+	MaxDistance int      `json:"max_distance"`
+	MinRating   float64  `json:"min_rating"`
+	Keywords    []string `json:"keywords"`
+	MaxPrice    float64  `json:"max_price,omitempty"`
+	SortBy      string   `json:"sort_by,omitempty"`
+
+	PartySize     int    `json:"party_size,omitempty"`
+	Equipment     string `json:"equipment,omitempty"`
+	VehicleLength int    `json:"vehicle_length,omitempty"`
+}
+
+// Source is the JSON representation of a metadata source
+type Source struct {
+	Name       string  `json:"name"`
+	URL        string  `json:"url,omitempty"`
+	RatingMax  float64 `json:"rating_max"`
+	RatingDesc string  `json:"rating_desc,omitempty"`
+}
+
+// Result is the JSON representation of an available campground
+type Result struct {
+	Name     string   `json:"name"`
+	ResURL   string   `json:"res_url"`
+	ResID    string   `json:"res_id"`
+	URL      string   `json:"url,omitempty"`
+	ImageURL string   `json:"image_url,omitempty"`
+	Desc     string   `json:"desc,omitempty"`
+	Locale   string   `json:"locale,omitempty"`
+	Distance float64  `json:"distance"`
+	Rating   float64  `json:"rating"`
+	Features []string `json:"features"`
+
+	Ratings []SourceRating `json:"ratings"`
+
+	Availability    []Availability `json:"availability"`
+	KnownCampground *Campground    `json:"known_campground,omitempty"`
+	MatchConfidence float64        `json:"match_confidence,omitempty"`
+}
+
+// SourceRating is the JSON representation of a single source's contribution to a rating
+type SourceRating struct {
+	Source     string  `json:"source"`
+	Name       string  `json:"name"`
+	Rating     float64 `json:"rating"`
+	RatingMax  float64 `json:"rating_max"`
+	Normalized float64 `json:"normalized"`
+	Weight     float64 `json:"weight"`
+}
+
+// Availability is the JSON representation of sites available on a date
+type Availability struct {
+	Date      string `json:"date"`
+	Kind      string `json:"kind"`
+	KindName  string `json:"kind_name"`
+	Name      string `json:"name,omitempty"`
+	Desc      string `json:"desc,omitempty"`
+	SpotCount int    `json:"spot_count"`
+	Sites     []Site `json:"sites,omitempty"`
+	URL       string `json:"url,omitempty"`
+	// PricePerNight and TotalPrice are in dollars, and omitted when unknown
+	PricePerNight float64 `json:"price_per_night,omitempty"`
+	TotalPrice    float64 `json:"total_price,omitempty"`
+}
+
+// Site is the JSON representation of an individual campsite
+type Site struct {
+	ID               string   `json:"id"`
+	Loop             string   `json:"loop,omitempty"`
+	MaxOccupancy     int      `json:"max_occupancy,omitempty"`
+	MaxVehicleLength int      `json:"max_vehicle_length,omitempty"`
+	Hookups          []string `json:"hookups,omitempty"`
+	Accessible       bool     `json:"accessible"`
+}
+
+// Campground is the JSON representation of a campground found within the metadata
+type Campground struct {
+	ID         string         `json:"id"`
+	PropertyID string         `json:"property_id,omitempty"`
+	Name       string         `json:"name"`
+	URL        string         `json:"url,omitempty"`
+	Refs       map[string]Ref `json:"refs"`
+}
+
+// Ref is the JSON representation of a metadata cross-reference
+type Ref struct {
+	Name   string    `json:"name,omitempty"`
+	URL    string    `json:"url,omitempty"`
+	Rating float64   `json:"rating"`
+	Locale string    `json:"locale,omitempty"`
+	Lists  []RefList `json:"lists,omitempty"`
+}
+
+// RefList is the JSON representation of an award referenced by a campground
+type RefList struct {
+	Title string `json:"title"`
+	Place int    `json:"place"`
+}
+
+// NewDocument converts a render context into its JSON representation
+func NewDocument(c Context) Document {
+	d := Document{
+		Version: SchemaVersion,
+		Query: Query{
+			Lat:         c.Query.Lat,
+			Lon:         c.Query.Lon,
+			Dates:       []string{},
+			StayLength:  c.Query.StayLength,
+			MaxDistance: c.Query.MaxDistance,
+			MinRating:   c.Query.MinRating,
+			Keywords:    []string{},
+			MaxPrice:    c.Query.MaxPrice,
+			SortBy:      c.Query.SortBy,
+
+			PartySize:     c.Query.PartySize,
+			Equipment:     c.Query.Equipment,
+			VehicleLength: c.Query.VehicleLength,
+		},
+		Sources: map[string]Source{},
+		Results: []Result{},
+		Errors:  []string{},
+	}
+
+	for _, t := range c.Query.Dates {
+		d.Query.Dates = append(d.Query.Dates, t.Format(dateFormat))
+	}
+
+	if !c.Query.From.IsZero() {
+		d.Query.From = c.Query.From.Format(dateFormat)
+	}
+	if !c.Query.Until.IsZero() {
+		d.Query.Until = c.Query.Until.Format(dateFormat)
+	}
+	for _, w := range c.Query.Weekdays {
+		d.Query.Weekdays = append(d.Query.Weekdays, w.String())
+	}
+
+	for _, k := range c.Query.Keywords {
+		if k != "" {
+			d.Query.Keywords = append(d.Query.Keywords, k)
+		}
+	}
+
+	for k, s := range c.Sources {
+		d.Sources[k] = Source{Name: s.Name, URL: s.URL, RatingMax: s.RatingMax, RatingDesc: s.RatingDesc}
+	}
+
+	for _, r := range c.Results {
+		d.Results = append(d.Results, newResult(r))
+	}
+
+	for _, err := range c.Errors {
+		d.Errors = append(d.Errors, err.Error())
+	}
+
+	return d
+}
+
+// newResult converts a result into its JSON representation
+func newResult(r campwiz.Result) Result {
+	jr := Result{
+		Name:         r.Name,
+		ResURL:       r.ResURL,
+		ResID:        r.ResID,
+		URL:          r.URL,
+		ImageURL:     r.ImageURL,
+		Desc:         r.Desc,
+		Locale:       r.Locale,
+		Distance:     r.Distance,
+		Rating:       r.Rating,
+		Features:     []string{},
+		Ratings:      []SourceRating{},
+		Availability: []Availability{},
+	}
+
+	jr.Features = append(jr.Features, r.Features...)
+
+	for _, sr := range r.Ratings {
+		jr.Ratings = append(jr.Ratings, SourceRating{
+			Source:     sr.Source,
+			Name:       sr.Name,
+			Rating:     sr.Rating,
+			RatingMax:  sr.RatingMax,
+			Normalized: sr.Normalized,
+			Weight:     sr.Weight,
+		})
+	}
+
+	for _, a := range r.Availability {
+		ja := Availability{
+			Date:      a.Date.Format(dateFormat),
+			Kind:      string(a.Kind),
+			KindName:  a.Kind.Name(),
+			Name:      a.Name,
+			Desc:      a.Desc,
+			SpotCount: a.SpotCount,
+			URL:       a.URL,
+
+			PricePerNight: a.PricePerNight,
+			TotalPrice:    a.TotalPrice,
+		}
+		for _, s := range a.Sites {
+			ja.Sites = append(ja.Sites, Site{
+				ID:               s.ID,
+				Loop:             s.Loop,
+				MaxOccupancy:     s.MaxOccupancy,
+				MaxVehicleLength: s.MaxVehicleLength,
+				Hookups:          s.Hookups,
+				Accessible:       s.Accessible,
+			})
+		}
+		jr.Availability = append(jr.Availability, ja)
+	}
+
+	if cg := r.KnownCampground; cg != nil {
+		jc := &Campground{
+			ID:         cg.ID,
+			PropertyID: cg.PropertyID,
+			Name:       cg.Name,
+			URL:        cg.URL,
+			Refs:       map[string]Ref{},
+		}
+
+		for k, ref := range cg.Refs {
+			jref := Ref{Name: ref.Name, URL: ref.URL, Rating: ref.Rating, Locale: ref.Locale}
+			for _, l := range ref.Lists {
+				jref.Lists = append(jref.Lists, RefList{Title: l.Title, Place: l.Place})
+			}
+			jc.Refs[k] = jref
+		}
+		jr.KnownCampground = jc
+		jr.MatchConfidence = r.MatchConfidence
+	}
+
+	return jr
+}
+
+// JSON writes search results as indented JSON
+func JSON(w io.Writer, c Context) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(NewDocument(c))
+}
@@ -0,0 +1,125 @@
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tstromberg/campwiz/pkg/campwiz"
+)
+
+func testContext() Context {
+	date := time.Date(2021, 2, 12, 0, 0, 0, 0, time.UTC)
+	return Context{
+		Query: campwiz.Query{
+			Lat:         37.4092297,
+			Lon:         -122.07237049999999,
+			Dates:       []time.Time{date},
+			StayLength:  2,
+			MaxDistance: 100,
+			Keywords:    []string{""},
+			PartySize:   4,
+			Equipment:   campwiz.EquipmentTent,
+		},
+		Sources: map[string]campwiz.Source{
+			"cc": {Name: "California Camping", RatingMax: 10, RatingDesc: "scenery"},
+		},
+		Results: []campwiz.Result{
+			{
+				ResURL:   "https://www.reservecalifornia.com/",
+				ResID:    "695",
+				Name:     "Portola Redwoods SP",
+				Distance: 6,
+				Rating:   7,
+				Ratings: []campwiz.SourceRating{
+					{Source: "cc", Name: "California Camping", Desc: "scenery", Rating: 7, RatingMax: 10, Normalized: 7, Weight: 1},
+				},
+				Availability: []campwiz.Availability{
+					{Kind: campwiz.Tent, Name: "Portola Campground", Desc: "Tent Campsite", SpotCount: 12, Date: date, PricePerNight: 35, TotalPrice: 70, Sites: []campwiz.Site{
+						{ID: "12", Loop: "Madrone", MaxOccupancy: 8, MaxVehicleLength: 24, Hookups: []string{"water"}},
+					}},
+				},
+				KnownCampground: &campwiz.Campground{
+					ID:         "portola",
+					PropertyID: "/ca/santa_cruz/portola",
+					Name:       "Portola Redwoods State Park",
+					Refs: map[string]*campwiz.Ref{
+						"cc": {Name: "Portola Redwoods State Park", Rating: 7, Lists: []campwiz.RefList{{Title: "Best Redwoods", Place: 3}}},
+					},
+				},
+			},
+		},
+		Errors: []error{fmt.Errorf("scc list: timed out")},
+	}
+}
+
+func TestNewDocument(t *testing.T) {
+	got := NewDocument(testContext())
+
+	want := Document{
+		Version: SchemaVersion,
+		Query: Query{
+			Lat:         37.4092297,
+			Lon:         -122.07237049999999,
+			Dates:       []string{"2021-02-12"},
+			StayLength:  2,
+			MaxDistance: 100,
+			Keywords:    []string{},
+			PartySize:   4,
+			Equipment:   "tent",
+		},
+		Sources: map[string]Source{
+			"cc": {Name: "California Camping", RatingMax: 10, RatingDesc: "scenery"},
+		},
+		Results: []Result{
+			{
+				Name:     "Portola Redwoods SP",
+				ResURL:   "https://www.reservecalifornia.com/",
+				ResID:    "695",
+				Distance: 6,
+				Rating:   7,
+				Features: []string{},
+				Ratings: []SourceRating{
+					{Source: "cc", Name: "California Camping", Rating: 7, RatingMax: 10, Normalized: 7, Weight: 1},
+				},
+				Availability: []Availability{
+					{Date: "2021-02-12", Kind: "⛺", KindName: "tent", Name: "Portola Campground", Desc: "Tent Campsite", SpotCount: 12, PricePerNight: 35, TotalPrice: 70, Sites: []Site{
+						{ID: "12", Loop: "Madrone", MaxOccupancy: 8, MaxVehicleLength: 24, Hookups: []string{"water"}},
+					}},
+				},
+				KnownCampground: &Campground{
+					ID:         "portola",
+					PropertyID: "/ca/santa_cruz/portola",
+					Name:       "Portola Redwoods State Park",
+					Refs: map[string]Ref{
+						"cc": {Name: "Portola Redwoods State Park", Rating: 7, Lists: []RefList{{Title: "Best Redwoods", Place: 3}}},
+					},
+				},
+			},
+		},
+		Errors: []string{"scc list: timed out"},
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("NewDocument() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := JSON(&buf, testContext()); err != nil {
+		t.Fatalf("JSON: %v", err)
+	}
+
+	var got Document
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("unmarshal: %v\n%s", err, buf.Bytes())
+	}
+
+	if diff := cmp.Diff(NewDocument(testContext()), got); diff != "" {
+		t.Errorf("JSON() round-trip mismatch (-want +got):\n%s", diff)
+	}
+}
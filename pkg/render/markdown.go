@@ -0,0 +1,54 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// mdEscape escapes text for use within a Markdown table cell
+func mdEscape(s string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ", "[", `\[`, "]", `\]`).Replace(s)
+}
+
+// Markdown writes search results as a Markdown table
+func Markdown(w io.Writer, c Context) error {
+	d := NewDocument(c)
+
+	var b strings.Builder
+	b.WriteString("| Name | Distance | Rating | Availability | Ratings |\n")
+	b.WriteString("|------|---------:|-------:|--------------|---------|\n")
+
+	for _, r := range d.Results {
+		name := mdEscape(r.Name)
+		if r.URL != "" {
+			name = fmt.Sprintf("[%s](%s)", name, r.URL)
+		}
+
+		var avail []string
+		for _, a := range r.Availability {
+			desc := fmt.Sprintf("%s: %dx%s", a.Date, a.SpotCount, a.Kind)
+			if a.URL != "" {
+				desc = fmt.Sprintf("[%s](%s)", desc, a.URL)
+			}
+			avail = append(avail, desc)
+		}
+
+		var ratings []string
+		for _, sr := range r.Ratings {
+			ratings = append(ratings, fmt.Sprintf("%s: %.0f/%.0f", mdEscape(sr.Name), sr.Rating, sr.RatingMax))
+		}
+
+		fmt.Fprintf(&b, "| %s | %.0fmi | %.1f | %s | %s |\n", name, r.Distance, r.Rating, strings.Join(avail, "<br>"), strings.Join(ratings, "<br>"))
+	}
+
+	if len(d.Errors) > 0 {
+		b.WriteString("\n**Errors:**\n\n")
+		for _, e := range d.Errors {
+			fmt.Fprintf(&b, "* %s\n", mdEscape(e))
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
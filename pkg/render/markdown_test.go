@@ -0,0 +1,27 @@
+package render
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Markdown(&buf, testContext()); err != nil {
+		t.Fatalf("Markdown: %v", err)
+	}
+
+	want := `| Name | Distance | Rating | Availability | Ratings |
+|------|---------:|-------:|--------------|---------|
+| Portola Redwoods SP | 6mi | 7.0 | 2021-02-12: 12x⛺ | California Camping: 7/10 |
+
+**Errors:**
+
+* scc list: timed out
+`
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("Markdown() mismatch (-want +got):\n%s", diff)
+	}
+}
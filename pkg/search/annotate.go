@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/tstromberg/campwiz/pkg/campwiz"
+	"github.com/tstromberg/campwiz/pkg/geo"
 	"github.com/tstromberg/campwiz/pkg/mangle"
 	"github.com/tstromberg/campwiz/pkg/metadata"
 
@@ -51,35 +52,39 @@ var scoreNames = map[int]string{
 	SiteID:                "SiteID",
 }
 
+const (
+	// confirmMiles is how close a result must be to a campground for the location to confirm a name match
+	confirmMiles = 3.0
+
+	// rejectMiles is how far a result may be from a campground before a name match is rejected
+	rejectMiles = 25.0
+)
+
 type Match struct {
 	Score      int
 	Detail     string
 	Campground *campwiz.Campground
-}
 
-func average(xs []float64) float64 {
-	total := 0.0
-	for _, v := range xs {
-		total += v
-	}
-	return total / float64(len(xs))
+	// Located is true if both the result and campground have known coordinates
+	Located bool
+	// Miles is the distance between the result and campground, if Located
+	Miles float64
+	// Confidence is how sure we are of this match, from 0 to 1
+	Confidence float64
 }
 
-func annotate(r campwiz.Result, props map[string]*campwiz.Property) campwiz.Result {
-	cg := findBestMatch(r, props)
+func annotate(r campwiz.Result, idx *Index) campwiz.Result {
+	cg := findBestMatch(r, idx)
 	if cg.Score == 0 {
 		klog.Warningf("No site match for %+v", r)
 		return r
 	}
 	r.KnownCampground = cg.Campground
+	r.MatchConfidence = cg.Confidence
 
-	ratings := []float64{}
+	r.Ratings, r.Rating = rate(cg.Campground, idx.Sources())
 
 	for _, ref := range cg.Campground.Refs {
-		if ref.Rating > 0 {
-			// TODO: Take into account max
-			ratings = append(ratings, ref.Rating)
-		}
 		if r.Locale == "" && ref.Locale != "" {
 			r.Locale = ref.Locale
 		}
@@ -96,29 +101,121 @@ func annotate(r campwiz.Result, props map[string]*campwiz.Property) campwiz.Resu
 		}
 	}
 
-	r.Rating = average(ratings)
 	return r
 }
 
-func findBestMatch(r campwiz.Result, props map[string]*campwiz.Property) Match {
-	matches := findMatches(r, props)
-
+func findBestMatch(r campwiz.Result, idx *Index) Match {
+	matches := rankMatches(r, idx)
 	if len(matches) == 0 {
 		return Match{Score: NoMatch}
 	}
-
-	sort.Slice(matches, func(i, j int) bool { return matches[i].Score > matches[j].Score })
 	return matches[0]
 }
 
-var (
-	varCache = map[string][]string{}
-)
+// rankMatches returns all plausible matches for a result, best first
+func rankMatches(r campwiz.Result, idx *Index) []Match {
+	if cg := idx.byResID(r); cg != nil {
+		return []Match{{Score: SiteID, Detail: fmt.Sprintf("reservation id %q at %s", strings.TrimSpace(r.ResID), ResHost(r.ResURL)), Campground: cg, Confidence: 1}}
+	}
 
-func variations(s string) []string {
-	if varCache[s] != nil {
-		return varCache[s]
+	matches := locate(r, findMatches(r, idx))
+	sort.SliceStable(matches, func(i, j int) bool { return better(matches[i], matches[j]) })
+	return matches
+}
+
+// better returns true if match a should be preferred over match b
+func better(a Match, b Match) bool {
+	if a.Confidence != b.Confidence {
+		return a.Confidence > b.Confidence
+	}
+	if a.Score != b.Score {
+		return a.Score > b.Score
+	}
+	// Break ties by proximity, preferring matches that can be located at all
+	if a.Located != b.Located {
+		return a.Located
+	}
+	return a.Miles < b.Miles
+}
+
+// locate uses the distance between a result and its matched campgrounds to confirm or reject name matches
+func locate(r campwiz.Result, ms []Match) []Match {
+	located := []Match{}
+	for _, m := range ms {
+		m.Confidence = float64(m.Score) / float64(SiteID)
+
+		lat, lon := location(m.Campground)
+		if r.Lat == 0 || r.Lon == 0 || lat == 0 || lon == 0 {
+			located = append(located, m)
+			continue
+		}
+
+		m.Located = true
+		m.Miles = geo.MilesApart(r.Lat, r.Lon, lat, lon)
+		if m.Miles > rejectMiles {
+			klog.V(1).Infof("rejecting %s match for %q: %q is %.1f miles away", scoreNames[m.Score], r.Name, m.Campground.Name, m.Miles)
+			continue
+		}
+
+		// Closer campgrounds are more likely to be the right one, and anything within confirmMiles is near-certain
+		closeness := 1.0
+		if m.Miles > confirmMiles {
+			closeness = (rejectMiles - m.Miles) / (rejectMiles - confirmMiles)
+		}
+		m.Confidence += (1 - m.Confidence) * closeness * 0.9
+		m.Detail = fmt.Sprintf("%s (%.1f miles away)", m.Detail, m.Miles)
+		located = append(located, m)
+	}
+	return located
+}
+
+// location returns the coordinates of a campground, as recorded by its refs
+func location(cg *campwiz.Campground) (float64, float64) {
+	if cg == nil {
+		return 0, 0
 	}
+
+	keys := []string{}
+	for k := range cg.Refs {
+		keys = append(keys, k)
+	}
+	// Refs is a map: consult it in a stable order
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		ref := cg.Refs[k]
+		if ref.Lat != 0 && ref.Lon != 0 {
+			return ref.Lat, ref.Lon
+		}
+	}
+	return 0, 0
+}
+
+// nearest returns the campground within a property closest to the result, or the last one if none can be located
+func nearest(r campwiz.Result, prop *campwiz.Property) *campwiz.Campground {
+	var cg *campwiz.Campground
+	best := -1.0
+
+	for _, c := range prop.Campgrounds {
+		if best < 0 {
+			cg = c
+		}
+
+		lat, lon := location(c)
+		if r.Lat == 0 || r.Lon == 0 || lat == 0 || lon == 0 {
+			continue
+		}
+
+		if d := geo.MilesApart(r.Lat, r.Lon, lat, lon); best < 0 || d < best {
+			cg = c
+			best = d
+		}
+	}
+	return cg
+}
+
+// variations returns alternate spellings of a normalized name, in a stable order
+func variations(s string) []string {
 	try := map[string]bool{
 		strings.ToLower(strings.Join(strings.Split(mangle.Shortest(mangle.Expand(s)), " "), "")): true,
 		strings.ToLower(mangle.Shortest(s)):                true,
@@ -127,112 +224,110 @@ func variations(s string) []string {
 	}
 
 	vs := []string{}
-	for k, _ := range try {
+	for k := range try {
 		vs = append(vs, k)
 	}
+	sort.Strings(vs)
 
-	klog.Infof("variations for %q: %v", s, vs)
-	varCache[s] = vs
-	return varCache[s]
+	klog.V(2).Infof("variations for %q: %v", s, vs)
+	return vs
 }
 
-func findMatches(r campwiz.Result, props map[string]*campwiz.Property) []Match {
+func findMatches(r campwiz.Result, idx *Index) []Match {
 	var matches []Match
 	resName := mangle.Normalize(r.Name)
+	resVars := variations(resName)
 
-	for _, prop := range props {
-		propName := mangle.Normalize(prop.Name)
-		// TODO: A better job guessing which campsite to use
-		var cg *campwiz.Campground
-		for _, c := range prop.Campgrounds {
-			cg = c
-		}
+	for _, ip := range idx.candidates(resName, resVars) {
+		prop := ip.prop
+		propName := ip.name
+		cg := nearest(r, prop)
 
 		if resName == propName {
 			if len(prop.Campgrounds) == 1 {
-				matches = append(matches, Match{SinglePropMatch, fmt.Sprintf("result %q = single park %q", resName, prop.Name), cg})
+				matches = append(matches, Match{Score: SinglePropMatch, Detail: fmt.Sprintf("result %q = single park %q", resName, prop.Name), Campground: cg})
 			} else {
-				matches = append(matches, Match{PropMatch, fmt.Sprintf("result %q = multi park %q", resName, prop.Name), cg})
+				matches = append(matches, Match{Score: PropMatch, Detail: fmt.Sprintf("result %q = multi park %q", resName, prop.Name), Campground: cg})
 			}
 		}
 
-		for x, kv := range variations(propName) {
+		for x, kv := range ip.vars {
 			if kv == resName {
-				matches = append(matches, Match{MangledPropMatch, fmt.Sprintf("variation %d: %q = %q", x, kv, resName), cg})
+				matches = append(matches, Match{Score: MangledPropMatch, Detail: fmt.Sprintf("variation %d: %q = %q", x, kv, resName), Campground: cg})
 			}
 
-			for i, rv := range variations(resName) {
+			for i, rv := range resVars {
 				if rv == kv {
-					matches = append(matches, Match{BiMangledPropMatch, fmt.Sprintf("variation %d/%d: %q = %q", i, x, rv, propName), cg})
+					matches = append(matches, Match{Score: BiMangledPropMatch, Detail: fmt.Sprintf("variation %d/%d: %q = %q", i, x, rv, propName), Campground: cg})
 				}
 
 				if strings.Contains(kv, rv) {
-					matches = append(matches, Match{BiMangledPropSubMatch, fmt.Sprintf("variation %d/%d: result %q in known %q", i, x, rv, kv), cg})
+					matches = append(matches, Match{Score: BiMangledPropSubMatch, Detail: fmt.Sprintf("variation %d/%d: result %q in known %q", i, x, rv, kv), Campground: cg})
 					continue
 				}
 				if strings.Contains(rv, kv) {
-					matches = append(matches, Match{BiMangledPropSubMatch, fmt.Sprintf("variation %d/%d: result %q in known %q", i, x, kv, rv), cg})
+					matches = append(matches, Match{Score: BiMangledPropSubMatch, Detail: fmt.Sprintf("variation %d/%d: result %q in known %q", i, x, kv, rv), Campground: cg})
 					continue
 				}
 
 				d := levenshtein.ComputeDistance(rv, kv)
-				if d < 3 {
-					matches = append(matches, Match{ApproxPropMatch, fmt.Sprintf("variation %d/%d: %q is %d edits from %q", i, x, rv, d, kv), cg})
+				if d <= maxEdits {
+					matches = append(matches, Match{Score: ApproxPropMatch, Detail: fmt.Sprintf("variation %d/%d: %q is %d edits from %q", i, x, rv, d, kv), Campground: cg})
 					continue
 				}
 			}
 
 		}
 
-		for _, cg := range prop.Campgrounds {
-			knownName := mangle.Normalize(cg.Name)
+		for _, ic := range ip.campgrounds {
+			cg := ic.cg
+			knownName := ic.name
 
 			if resName == knownName {
-				matches = append(matches, Match{NameMatch, fmt.Sprintf("result %q = known %q", resName, knownName), cg})
+				matches = append(matches, Match{Score: NameMatch, Detail: fmt.Sprintf("result %q = known %q", resName, knownName), Campground: cg})
 				continue
 			}
 
 			if strings.Contains(resName, knownName) {
-				matches = append(matches, Match{SubMatch, fmt.Sprintf("known %q in result %q", knownName, resName), cg})
+				matches = append(matches, Match{Score: SubMatch, Detail: fmt.Sprintf("known %q in result %q", knownName, resName), Campground: cg})
 			}
 
 			if strings.Contains(knownName, resName) {
-				matches = append(matches, Match{SubMatch, fmt.Sprintf("result %q in known %q", resName, knownName), cg})
+				matches = append(matches, Match{Score: SubMatch, Detail: fmt.Sprintf("result %q in known %q", resName, knownName), Campground: cg})
 			}
 
-			for i, rv := range variations(resName) {
+			for i, rv := range resVars {
 				if rv == knownName {
-					matches = append(matches, Match{MangledMatch, fmt.Sprintf("variation %d: %q = %q", i, rv, knownName), cg})
+					matches = append(matches, Match{Score: MangledMatch, Detail: fmt.Sprintf("variation %d: %q = %q", i, rv, knownName), Campground: cg})
 					continue
 				}
 
 				if strings.Contains(knownName, rv) {
-					matches = append(matches, Match{MangledSubMatch, fmt.Sprintf("variation %d: result %q in known %q", i, rv, knownName), cg})
+					matches = append(matches, Match{Score: MangledSubMatch, Detail: fmt.Sprintf("variation %d: result %q in known %q", i, rv, knownName), Campground: cg})
 					continue
 				}
 				if strings.Contains(rv, knownName) {
-					matches = append(matches, Match{MangledSubMatch, fmt.Sprintf("variation %d: result %q in known %q", i, knownName, rv), cg})
+					matches = append(matches, Match{Score: MangledSubMatch, Detail: fmt.Sprintf("variation %d: result %q in known %q", i, knownName, rv), Campground: cg})
 					continue
 				}
 
-				for x, kv := range variations(knownName) {
-					kv = strings.ToLower(kv)
+				for x, kv := range ic.vars {
 					if rv == kv {
-						matches = append(matches, Match{BiMangledMatch, fmt.Sprintf("variation %d/%d: %q = %q", i, x, rv, knownName), cg})
+						matches = append(matches, Match{Score: BiMangledMatch, Detail: fmt.Sprintf("variation %d/%d: %q = %q", i, x, rv, knownName), Campground: cg})
 						continue
 					}
 					if strings.Contains(kv, rv) {
-						matches = append(matches, Match{BiMangledSubMatch, fmt.Sprintf("variation %d/%d: result %q in known %q", i, x, rv, kv), cg})
+						matches = append(matches, Match{Score: BiMangledSubMatch, Detail: fmt.Sprintf("variation %d/%d: result %q in known %q", i, x, rv, kv), Campground: cg})
 						continue
 					}
 					if strings.Contains(rv, kv) {
-						matches = append(matches, Match{BiMangledSubMatch, fmt.Sprintf("variation %d/%d: result %q in known %q", i, x, kv, rv), cg})
+						matches = append(matches, Match{Score: BiMangledSubMatch, Detail: fmt.Sprintf("variation %d/%d: result %q in known %q", i, x, kv, rv), Campground: cg})
 						continue
 					}
 
 					d := levenshtein.ComputeDistance(rv, kv)
-					if d < 3 {
-						matches = append(matches, Match{ApproxMatch, fmt.Sprintf("variation %d/%d: %q is %d edits from %q", i, x, rv, d, kv), cg})
+					if d <= maxEdits {
+						matches = append(matches, Match{Score: ApproxMatch, Detail: fmt.Sprintf("variation %d/%d: %q is %d edits from %q", i, x, rv, d, kv), Campground: cg})
 						continue
 					}
 
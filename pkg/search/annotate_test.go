@@ -106,7 +106,7 @@ func TestFindBestMatch(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.in, func(t *testing.T) {
-			got := findBestMatch(campwiz.Result{Name: tt.in}, props)
+			got := findBestMatch(campwiz.Result{Name: tt.in}, NewIndex(nil, props))
 			if got.Score != tt.score {
 				t.Errorf("got score %d %q, want %d %q: %+v", got.Score, scoreNames[got.Score], tt.score, scoreNames[tt.score], got)
 			}
@@ -122,3 +122,83 @@ func TestFindBestMatch(t *testing.T) {
 		})
 	}
 }
+
+func TestFindBestMatchLocation(t *testing.T) {
+	lake := func(id string, lat float64, lon float64) *campwiz.Property {
+		return &campwiz.Property{
+			ID:   id,
+			Name: id,
+			Campgrounds: []*campwiz.Campground{{
+				ID:   id,
+				Name: "Lake Campground",
+				Refs: map[string]*campwiz.Ref{
+					"cc": {Name: "Lake Campground", Lat: lat, Lon: lon},
+				},
+			}},
+		}
+	}
+
+	props := map[string]*campwiz.Property{
+		"/ca/tahoe/lake":  lake("/ca/tahoe/lake", 39.0968, -120.0324),
+		"/ca/shasta/lake": lake("/ca/shasta/lake", 40.7187, -122.4211),
+		"/ca/sierra/lake": lake("/ca/sierra/lake", 0, 0),
+	}
+
+	tests := []struct {
+		name    string
+		lat     float64
+		lon     float64
+		score   int
+		id      string
+		located bool
+	}{
+		{"near tahoe", 39.1, -120.03, NameMatch, "/ca/tahoe/lake", true},
+		{"near shasta", 40.7, -122.4, NameMatch, "/ca/shasta/lake", true},
+		{"far from all", 34.05, -118.24, NameMatch, "/ca/sierra/lake", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := findBestMatch(campwiz.Result{Name: "Lake Campground", Lat: tt.lat, Lon: tt.lon}, NewIndex(nil, props))
+			if got.Score != tt.score {
+				t.Errorf("got score %d %q, want %d %q: %+v", got.Score, scoreNames[got.Score], tt.score, scoreNames[tt.score], got)
+			}
+			if got.Campground == nil || got.Campground.ID != tt.id {
+				t.Fatalf("got campground %+v, expected %q", got.Campground, tt.id)
+			}
+			if got.Located != tt.located {
+				t.Errorf("got located=%v, want %v", got.Located, tt.located)
+			}
+		})
+	}
+}
+
+func TestLocateConfidence(t *testing.T) {
+	cg := &campwiz.Campground{
+		Name: "Upper Pines",
+		Refs: map[string]*campwiz.Ref{"cc": {Lat: 37.7362, Lon: -119.5637}},
+	}
+	r := campwiz.Result{Name: "Upper Pines"}
+
+	unlocated := locate(r, []Match{{Score: SubMatch, Campground: cg}})
+	r.Lat, r.Lon = 37.74, -119.56
+	near := locate(r, []Match{{Score: SubMatch, Campground: cg}})
+	r.Lat, r.Lon = 37.9, -119.8
+	further := locate(r, []Match{{Score: SubMatch, Campground: cg}})
+	r.Lat, r.Lon = 36.6, -121.9
+	far := locate(r, []Match{{Score: SubMatch, Campground: cg}})
+
+	if len(unlocated) != 1 || len(near) != 1 || len(further) != 1 {
+		t.Fatalf("unexpected rejection: unlocated=%+v near=%+v further=%+v", unlocated, near, further)
+	}
+	if len(far) != 0 {
+		t.Errorf("far match was not rejected: %+v", far)
+	}
+
+	if !(near[0].Confidence > further[0].Confidence && further[0].Confidence > unlocated[0].Confidence) {
+		t.Errorf("confidence out of order: near=%.2f further=%.2f unlocated=%.2f", near[0].Confidence, further[0].Confidence, unlocated[0].Confidence)
+	}
+	if near[0].Confidence > 1 {
+		t.Errorf("confidence %.2f > 1", near[0].Confidence)
+	}
+}
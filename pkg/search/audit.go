@@ -0,0 +1,79 @@
+package search
+
+import (
+	"context"
+	"sort"
+
+	"github.com/tstromberg/campwiz/pkg/cache"
+	"github.com/tstromberg/campwiz/pkg/campwiz"
+)
+
+// ambiguousMargin is how close in confidence two campgrounds may be before a match is considered ambiguous
+const ambiguousMargin = 0.05
+
+const (
+	// Unmatched results could not be tied to any known campground
+	Unmatched = "unmatched"
+	// Ambiguous results matched multiple campgrounds with similar confidence
+	Ambiguous = "ambiguous"
+)
+
+// Finding is a result which could not be confidently tied to metadata
+type Finding struct {
+	Status string
+	Result campwiz.Result
+	// Matches holds the best match for each competing campground, best first
+	Matches []Match
+}
+
+// Audit searches without filters, returning results which are unmatched or ambiguously matched to metadata
+func Audit(ctx context.Context, providers []string, q campwiz.Query, cs cache.Store, idx *Index) ([]Finding, []error) {
+	q.Dates = q.ArrivalDates()
+	rs, errs := unfiltered(ctx, providers, q, cs)
+
+	fs := []Finding{}
+	for _, r := range rs {
+		if f, ok := audit(r, idx); ok {
+			fs = append(fs, f)
+		}
+	}
+
+	sort.SliceStable(fs, func(i, j int) bool {
+		if fs[i].Status != fs[j].Status {
+			return fs[i].Status > fs[j].Status
+		}
+		return fs[i].Result.Name < fs[j].Result.Name
+	})
+	return fs, errs
+}
+
+// audit returns a finding for a result, if it is unmatched or ambiguous
+func audit(r campwiz.Result, idx *Index) (Finding, bool) {
+	ms := rankMatches(r, idx)
+	if len(ms) == 0 {
+		return Finding{Status: Unmatched, Result: r}, true
+	}
+
+	// Keep only the best match for each campground
+	seen := map[*campwiz.Campground]bool{}
+	best := []Match{}
+	for _, m := range ms {
+		if seen[m.Campground] {
+			continue
+		}
+		seen[m.Campground] = true
+		best = append(best, m)
+	}
+
+	if len(best) < 2 || best[0].Confidence-best[1].Confidence > ambiguousMargin {
+		return Finding{}, false
+	}
+
+	competing := []Match{}
+	for _, m := range best {
+		if best[0].Confidence-m.Confidence <= ambiguousMargin {
+			competing = append(competing, m)
+		}
+	}
+	return Finding{Status: Ambiguous, Result: r, Matches: competing}, true
+}
@@ -0,0 +1,54 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/tstromberg/campwiz/pkg/campwiz"
+)
+
+func TestAudit(t *testing.T) {
+	lake := func(id string) *campwiz.Property {
+		return &campwiz.Property{
+			ID:          id,
+			Name:        id,
+			Campgrounds: []*campwiz.Campground{{ID: id, PropertyID: id, Name: "Lake Campground"}},
+		}
+	}
+
+	props := map[string]*campwiz.Property{
+		"/ca/tahoe/lake":  lake("/ca/tahoe/lake"),
+		"/ca/shasta/lake": lake("/ca/shasta/lake"),
+		"/ca/yosemite/pines": {
+			ID:          "/ca/yosemite/pines",
+			Name:        "Yosemite National Park",
+			Campgrounds: []*campwiz.Campground{{ID: "upper", Name: "Upper Pines"}},
+		},
+	}
+	idx := NewIndex(nil, props)
+
+	tests := []struct {
+		in      string
+		found   bool
+		status  string
+		matches int
+	}{
+		{"Upper Pines", false, "", 0},
+		{"Sad River", true, Unmatched, 0},
+		{"Lake Campground", true, Ambiguous, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, found := audit(campwiz.Result{Name: tt.in}, idx)
+			if found != tt.found {
+				t.Fatalf("audit(%q) found=%v, want %v: %+v", tt.in, found, tt.found, got)
+			}
+			if got.Status != tt.status {
+				t.Errorf("audit(%q) status=%q, want %q", tt.in, got.Status, tt.status)
+			}
+			if len(got.Matches) != tt.matches {
+				t.Errorf("audit(%q) got %d matches, want %d: %+v", tt.in, len(got.Matches), tt.matches, got.Matches)
+			}
+		})
+	}
+}
@@ -43,7 +43,141 @@ func filter(q campwiz.Query, rs []campwiz.Result) []campwiz.Result {
 				continue
 			}
 		}
+
+		if len(q.SiteKinds) > 0 {
+			r.Availability = wantedKinds(q.SiteKinds, r.Availability)
+			if len(r.Availability) == 0 {
+				klog.V(1).Infof("filtering %q -- no %v sites available", r.Name, q.SiteKinds)
+				continue
+			}
+		}
+
+		if q.MaxPrice > 0 {
+			r.Availability = affordable(q.MaxPrice, r.Availability)
+			if len(r.Availability) == 0 {
+				klog.V(1).Infof("filtering %q -- no sites under $%.2f/night", r.Name, q.MaxPrice)
+				continue
+			}
+		}
+
+		if q.PartySize > 0 || q.Equipment != "" || q.VehicleLength > 0 {
+			r.Availability = fitting(q, r.Availability)
+			if len(r.Availability) == 0 {
+				klog.V(1).Infof("filtering %q -- no sites fit %d people, %q equipment of %dft", r.Name, q.PartySize, q.Equipment, q.VehicleLength)
+				continue
+			}
+		}
+
+		if missing := missingFeatures(q.Features, r); len(missing) > 0 {
+			klog.V(1).Infof("filtering %q -- missing features %v", r.Name, missing)
+			continue
+		}
+
 		fs = append(fs, r)
 	}
 	return fs
 }
+
+// wantedKinds returns the availability entries for the requested site kinds
+func wantedKinds(kinds []campwiz.SiteKind, as []campwiz.Availability) []campwiz.Availability {
+	want := map[campwiz.SiteKind]bool{}
+	for _, k := range kinds {
+		want[k] = true
+	}
+
+	var found []campwiz.Availability
+	for _, a := range as {
+		if want[a.Kind] {
+			found = append(found, a)
+		}
+	}
+	return found
+}
+
+// affordable returns the availability entries which cost at most max per night, or whose price is unknown
+func affordable(max float64, as []campwiz.Availability) []campwiz.Availability {
+	var found []campwiz.Availability
+	for _, a := range as {
+		if a.PricePerNight <= max {
+			found = append(found, a)
+		}
+	}
+	return found
+}
+
+// fitting returns the availability entries whose kind suits the party's equipment, narrowed to the sites which fit.
+// Sites are kept when their limits are unknown, as most providers do not list them.
+func fitting(q campwiz.Query, as []campwiz.Availability) []campwiz.Availability {
+	var found []campwiz.Availability
+	for _, a := range as {
+		if !a.Kind.Fits(q.Equipment) {
+			continue
+		}
+		if len(a.Sites) == 0 {
+			found = append(found, a)
+			continue
+		}
+
+		var sites []campwiz.Site
+		for _, s := range a.Sites {
+			if fits(q, s) {
+				sites = append(sites, s)
+			}
+		}
+		if len(sites) == 0 {
+			continue
+		}
+		if len(sites) < len(a.Sites) {
+			a.SpotCount = len(sites)
+		}
+		a.Sites = sites
+		found = append(found, a)
+	}
+	return found
+}
+
+// fits returns true if a site may hold the party and its vehicle
+func fits(q campwiz.Query, s campwiz.Site) bool {
+	if q.PartySize > 0 && s.MaxOccupancy > 0 && q.PartySize > s.MaxOccupancy {
+		return false
+	}
+	if q.Vehicle() && q.VehicleLength > 0 && s.MaxVehicleLength > 0 && q.VehicleLength > s.MaxVehicleLength {
+		return false
+	}
+	return true
+}
+
+// missingFeatures returns the requested features that a result lacks
+func missingFeatures(features []int, r campwiz.Result) []int {
+	if len(features) == 0 {
+		return nil
+	}
+
+	have := []string{}
+	for _, f := range r.Features {
+		have = append(have, strings.ToLower(f))
+	}
+	if r.KnownCampground != nil {
+		for _, x := range r.KnownCampground.Refs {
+			for _, f := range x.Features {
+				have = append(have, strings.ToLower(f))
+			}
+		}
+	}
+
+	var missing []int
+	for _, f := range features {
+		found := false
+		for _, h := range have {
+			for _, kw := range campwiz.FeatureKeywords[f] {
+				if strings.Contains(h, kw) {
+					found = true
+				}
+			}
+		}
+		if !found {
+			missing = append(missing, f)
+		}
+	}
+	return missing
+}
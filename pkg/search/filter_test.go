@@ -2,62 +2,157 @@ package search
 
 import (
 	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tstromberg/campwiz/pkg/campwiz"
 )
 
 func TestFilter(t *testing.T) {
-	/* TODO: refactor for new result struct
-	as := []campwiz.AnnotatedResult{
+	rs := []campwiz.Result{
 		{
-			Result: campwiz.Result{
-				Name:     "pretty close",
-				Distance: 30.45,
-			},
-			Refs: []campwiz.Campground{
-				{
-					Source: campwiz.RefSource{
-						RatingDesc: "Scenery",
-					},
-					Rating: 7.0,
-					Desc:   "Tucked into a redwood forest",
-				},
+			Name:     "pretty close",
+			Distance: 30.45,
+			Rating:   7.0,
+			Desc:     "Tucked into a redwood forest",
+			Features: []string{"Hiking", "Picnic area"},
+			Availability: []campwiz.Availability{
+				{Kind: campwiz.Tent, SpotCount: 2, PricePerNight: 35, Sites: []campwiz.Site{{ID: "1", MaxOccupancy: 6}, {ID: "2", MaxOccupancy: 10}}},
+				{Kind: campwiz.RV, SpotCount: 5, PricePerNight: 50},
 			},
 		},
 		{
-			Result: campwiz.Result{
-				Name:     "ugly far",
-				Distance: 90.45,
+			Name:     "ugly far",
+			Distance: 90.45,
+			Rating:   2.0,
+			Desc:     "Hidden in an abandoned dump",
+			Availability: []campwiz.Availability{
+				{Kind: campwiz.RV, SpotCount: 1, PricePerNight: 20, Sites: []campwiz.Site{{ID: "A", MaxOccupancy: 4, MaxVehicleLength: 25}}},
+				{Kind: campwiz.Day, SpotCount: 8},
 			},
-			Refs: []campwiz.Campground{
-				{
-					Source: campwiz.RefSource{
-						RatingDesc: "Scenery",
-					},
-					Rating: 2.0,
-					Desc:   "Hidden in an abandoned dump",
+			KnownCampground: &campwiz.Campground{
+				Refs: map[string]*campwiz.Ref{
+					"cc": {Features: []string{"fishing", "swimming"}},
 				},
 			},
 		},
+		{
+			Name:     "walk-in beach",
+			Distance: 50,
+			Rating:   5.0,
+			Features: []string{"Bicycling", "Swimming"},
+			Availability: []campwiz.Availability{
+				{Kind: campwiz.Walk, SpotCount: 1},
+			},
+		},
 	}
 
 	var tests = []struct {
-		in  campwiz.Query
-		out []string
+		name string
+		in   campwiz.Query
+		out  []string
 	}{
-		{campwiz.Query{MaxDistance: 35.0}, []string{"pretty close"}},
+		{"none", campwiz.Query{}, []string{"pretty close", "ugly far", "walk-in beach"}},
+		{"distance", campwiz.Query{MaxDistance: 35}, []string{"pretty close"}},
+		{"rating", campwiz.Query{MinRating: 5}, []string{"pretty close", "walk-in beach"}},
+		{"keywords", campwiz.Query{Keywords: []string{"redwood"}}, []string{"pretty close"}},
+		{"tent or walk", campwiz.Query{SiteKinds: []campwiz.SiteKind{campwiz.Tent, campwiz.Walk}}, []string{"pretty close", "walk-in beach"}},
+		{"day", campwiz.Query{SiteKinds: []campwiz.SiteKind{campwiz.Day}}, []string{"ugly far"}},
+		{"boat", campwiz.Query{SiteKinds: []campwiz.SiteKind{campwiz.Boat}}, []string{}},
+		{"hiking", campwiz.Query{Features: []int{campwiz.Hiking}}, []string{"pretty close"}},
+		{"beach from refs", campwiz.Query{Features: []int{campwiz.Beach}}, []string{"ugly far", "walk-in beach"}},
+		{"beach and biking", campwiz.Query{Features: []int{campwiz.Beach, campwiz.Biking}}, []string{"walk-in beach"}},
+		{"fishing rv", campwiz.Query{Features: []int{campwiz.Fishing}, SiteKinds: []campwiz.SiteKind{campwiz.RV}}, []string{"ugly far"}},
+		{"cheap or unknown price", campwiz.Query{MaxPrice: 25}, []string{"ugly far", "walk-in beach"}},
+		{"cheap rv", campwiz.Query{MaxPrice: 40, SiteKinds: []campwiz.SiteKind{campwiz.RV}}, []string{"ugly far"}},
+		{"tent for 8", campwiz.Query{Equipment: campwiz.EquipmentTent, PartySize: 8}, []string{"pretty close", "walk-in beach"}},
+		{"30ft rv", campwiz.Query{Equipment: campwiz.EquipmentRV, VehicleLength: 30}, []string{"pretty close"}},
+		{"20ft trailer", campwiz.Query{Equipment: campwiz.EquipmentTrailer, VehicleLength: 20}, []string{"pretty close", "ugly far"}},
 	}
 
 	for _, tt := range tests {
-		t.Run(tt.in, func(t *testing.T) {
-			got := filter(tt.in)
+		t.Run(tt.name, func(t *testing.T) {
+			got := filter(tt.in, rs)
 			gotNames := []string{}
 			for _, r := range got {
-				gotNames = append(gotNames, got.Result.Name)
+				gotNames = append(gotNames, r.Name)
 			}
 
-			if gotNames != tt.out {
-				t.Errorf("got %q, want %q", gotNames, tt.out)
+			if diff := cmp.Diff(tt.out, gotNames); diff != "" {
+				t.Errorf("filter() mismatch (-want +got):\n%s", diff)
 			}
 		})
 	}
-	*/
+}
+
+func TestFilterSiteKinds(t *testing.T) {
+	rs := []campwiz.Result{
+		{
+			Name: "mixed",
+			Availability: []campwiz.Availability{
+				{Kind: campwiz.Tent, SpotCount: 2},
+				{Kind: campwiz.RV, SpotCount: 5},
+				{Kind: campwiz.Walk, SpotCount: 1},
+			},
+		},
+	}
+
+	got := filter(campwiz.Query{SiteKinds: []campwiz.SiteKind{campwiz.Tent, campwiz.Walk}}, rs)
+	want := []campwiz.Result{
+		{
+			Name: "mixed",
+			Availability: []campwiz.Availability{
+				{Kind: campwiz.Tent, SpotCount: 2},
+				{Kind: campwiz.Walk, SpotCount: 1},
+			},
+		},
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("filter() mismatch (-want +got):\n%s", diff)
+	}
+
+	if len(rs[0].Availability) != 3 {
+		t.Errorf("filter() modified its input: %+v", rs[0].Availability)
+	}
+}
+
+func TestFitting(t *testing.T) {
+	as := []campwiz.Availability{
+		{Kind: campwiz.Tent, SpotCount: 3, Sites: []campwiz.Site{{ID: "1", MaxOccupancy: 4}, {ID: "2", MaxOccupancy: 8}, {ID: "3"}}},
+		{Kind: campwiz.Group, SpotCount: 1, Sites: []campwiz.Site{{ID: "G", MaxOccupancy: 2}}},
+		{Kind: campwiz.Day, SpotCount: 4},
+	}
+
+	want := []campwiz.Availability{
+		{Kind: campwiz.Tent, SpotCount: 2, Sites: []campwiz.Site{{ID: "2", MaxOccupancy: 8}, {ID: "3"}}},
+	}
+
+	got := fitting(campwiz.Query{Equipment: campwiz.EquipmentTent, PartySize: 6}, as)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("fitting() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestFilterVehicleProviders(t *testing.T) {
+	rs := []campwiz.Result{
+		// ReserveCalifornia lists no sites, so the kind follows the equipment searched for
+		{Name: "Big Basin Redwoods SP", Availability: []campwiz.Availability{{Kind: campwiz.RV, SpotCount: 0}}},
+		// San Mateo County lists each site's maximum RV length
+		{Name: "Coyote Point", Availability: []campwiz.Availability{
+			{Kind: campwiz.RV, SpotCount: 1, Sites: []campwiz.Site{{ID: "1", MaxVehicleLength: 36, Hookups: []string{"electric"}}}},
+		}},
+		{Name: "Huddart Park", Availability: []campwiz.Availability{
+			{Kind: campwiz.Tent, SpotCount: 1, Sites: []campwiz.Site{{ID: "7"}}},
+		}},
+	}
+
+	got := []string{}
+	for _, r := range filter(campwiz.Query{Equipment: campwiz.EquipmentRV, VehicleLength: 30}, rs) {
+		got = append(got, r.Name)
+	}
+
+	want := []string{"Big Basin Redwoods SP", "Coyote Point"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("filter() mismatch (-want +got):\n%s", diff)
+	}
 }
@@ -0,0 +1,255 @@
+package search
+
+import (
+	"sort"
+	"unicode/utf8"
+
+	"github.com/tstromberg/campwiz/pkg/campwiz"
+	"github.com/tstromberg/campwiz/pkg/mangle"
+)
+
+const (
+	// maxEdits is the largest edit distance findMatches considers an approximate match
+	maxEdits = 2
+	// maxGramless is the longest name which may be within maxEdits of another without sharing a trigram
+	maxGramless = 2 + 3*maxEdits
+)
+
+// Index is a precomputed view of the known properties, used to find candidate matches for a result quickly
+type Index struct {
+	srcs  map[string]campwiz.Source
+	props []*indexedProperty
+
+	// names are the distinct names and variations of each property, which candidates are found by
+	names []indexedName
+	// grams maps a character trigram to the names containing it
+	grams map[string][]gramPosting
+	// byLen maps a short name length to the names of that length, which may be within a few edits of a result without sharing a trigram
+	byLen map[int][]int
+	// short are the properties with a name too short to have trigrams, which any result could contain
+	short []int
+	// ids maps a reservation site host and ID to the campground it identifies
+	ids map[string]*campwiz.Campground
+
+	// fullScan considers every property a candidate, for comparison in tests and benchmarks
+	fullScan bool
+}
+
+// indexedName is a name or variation belonging to a property
+type indexedName struct {
+	prop int
+	len  int
+}
+
+// gramPosting is a name containing a trigram, and how many times it does
+type gramPosting struct {
+	name  int
+	count int
+}
+
+type indexedProperty struct {
+	prop        *campwiz.Property
+	name        string
+	vars        []string
+	campgrounds []indexedCampground
+}
+
+type indexedCampground struct {
+	cg   *campwiz.Campground
+	name string
+	vars []string
+}
+
+// NewIndex builds an index of the given sources and properties
+func NewIndex(srcs map[string]campwiz.Source, props map[string]*campwiz.Property) *Index {
+	ids := []string{}
+	for id := range props {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	idx := &Index{
+		srcs:  srcs,
+		grams: map[string][]gramPosting{},
+		byLen: map[int][]int{},
+		ids:   map[string]*campwiz.Campground{},
+	}
+
+	for _, id := range ids {
+		prop := props[id]
+		n := len(idx.props)
+		ip := &indexedProperty{prop: prop, name: mangle.Normalize(prop.Name)}
+		ip.vars = variations(ip.name)
+		names := append([]string{ip.name}, ip.vars...)
+
+		for _, cg := range prop.Campgrounds {
+			ic := indexedCampground{cg: cg, name: mangle.Normalize(cg.Name)}
+			ic.vars = variations(ic.name)
+			for _, k := range resKeys(cg) {
+				idx.ids[k] = cg
+			}
+			ip.campgrounds = append(ip.campgrounds, ic)
+			names = append(names, ic.name)
+			names = append(names, ic.vars...)
+		}
+
+		seen := map[string]bool{}
+		for _, name := range names {
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			idx.addName(n, name)
+		}
+		idx.props = append(idx.props, ip)
+	}
+
+	return idx
+}
+
+// addName indexes a name or variation of property n
+func (idx *Index) addName(n int, name string) {
+	id := len(idx.names)
+	l := utf8.RuneCountInString(name)
+	idx.names = append(idx.names, indexedName{prop: n, len: l})
+
+	if l < 3 {
+		idx.short = addPosting(idx.short, n)
+		return
+	}
+	if l <= maxGramless {
+		idx.byLen[l] = append(idx.byLen[l], id)
+	}
+	for g, c := range trigrams(name) {
+		idx.grams[g] = append(idx.grams[g], gramPosting{name: id, count: c})
+	}
+}
+
+// trigrams returns the count of each character trigram within a string
+func trigrams(s string) map[string]int {
+	rs := []rune(s)
+	gs := map[string]int{}
+	for i := 0; i+3 <= len(rs); i++ {
+		gs[string(rs[i:i+3])]++
+	}
+	return gs
+}
+
+// addPosting appends a property to a posting list, unless it was the last one added
+func addPosting(ps []int, n int) []int {
+	if len(ps) > 0 && ps[len(ps)-1] == n {
+		return ps
+	}
+	return append(ps, n)
+}
+
+// Len returns the number of indexed properties
+func (idx *Index) Len() int {
+	if idx == nil {
+		return 0
+	}
+	return len(idx.props)
+}
+
+// Sources returns the metadata sources that ratings are drawn from
+func (idx *Index) Sources() map[string]campwiz.Source {
+	if idx == nil {
+		return nil
+	}
+	return idx.srcs
+}
+
+// byResID returns the campground explicitly mapped to a result's reservation ID, if any
+func (idx *Index) byResID(r campwiz.Result) *campwiz.Campground {
+	if idx == nil {
+		return nil
+	}
+	k := resKey(ResHost(r.ResURL), r.ResID)
+	if k == "" {
+		return nil
+	}
+	return idx.ids[k]
+}
+
+// candidates returns the properties with a name or variation which a result could match: one that is equal to,
+// contains, is contained by, or is within maxEdits of the result's name or variations. Names are compared by their
+// shared trigrams, which never rules out a property that findMatches could match.
+func (idx *Index) candidates(name string, vars []string) []*indexedProperty {
+	if idx == nil {
+		return nil
+	}
+	if idx.fullScan {
+		return idx.props
+	}
+
+	qs := append([]string{name}, vars...)
+	for _, q := range qs {
+		// Too short to have trigrams, so it may be within any name
+		if utf8.RuneCountInString(q) < 3 {
+			return idx.props
+		}
+	}
+
+	seen := map[int]bool{}
+	for _, p := range idx.short {
+		seen[p] = true
+	}
+
+	for _, q := range qs {
+		ql := utf8.RuneCountInString(q)
+		common := map[int]int{}
+		for g, c := range trigrams(q) {
+			for _, gp := range idx.grams[g] {
+				common[gp.name] += smaller(c, gp.count)
+			}
+		}
+
+		for id, shared := range common {
+			if possible(ql, idx.names[id].len, shared) {
+				seen[idx.names[id].prop] = true
+			}
+		}
+
+		// Short names may be within maxEdits while sharing no trigrams at all
+		for l := ql - maxEdits; l <= ql+maxEdits && ql <= maxGramless+maxEdits; l++ {
+			for _, id := range idx.byLen[l] {
+				if possible(ql, l, common[id]) {
+					seen[idx.names[id].prop] = true
+				}
+			}
+		}
+	}
+
+	ns := []int{}
+	for n := range seen {
+		ns = append(ns, n)
+	}
+	sort.Ints(ns)
+
+	ips := []*indexedProperty{}
+	for _, n := range ns {
+		ips = append(ips, idx.props[n])
+	}
+	return ips
+}
+
+// possible returns true if strings of lengths a and b sharing this many trigrams could contain one another,
+// or be within maxEdits. A string within another shares all of its trigrams, and by the q-gram lemma, strings
+// within k edits share at least max(a, b) - 2 - 3k trigrams.
+func possible(a int, b int, shared int) bool {
+	if shared >= smaller(a, b)-2 {
+		return true
+	}
+	if a-b > maxEdits || b-a > maxEdits {
+		return false
+	}
+	return shared >= a+b-smaller(a, b)-2-3*maxEdits
+}
+
+// smaller returns the smaller of two integers
+func smaller(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
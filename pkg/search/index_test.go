@@ -0,0 +1,169 @@
+package search
+
+import (
+	"runtime"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/tstromberg/campwiz/pkg/campwiz"
+	"github.com/tstromberg/campwiz/pkg/mangle"
+	"github.com/tstromberg/campwiz/pkg/metadata"
+)
+
+func TestIndexCandidates(t *testing.T) {
+	props := map[string]*campwiz.Property{
+		"/ca/sj/grant": {
+			ID:          "/ca/sj/grant",
+			Name:        "Joseph D. Grant County Park",
+			Campgrounds: []*campwiz.Campground{{ID: "grant", Name: "Joseph D. Grant County Park"}},
+		},
+		"/ca/yosemite/pines": {
+			ID:   "/ca/yosemite/pines",
+			Name: "Yosemite National Park",
+			Campgrounds: []*campwiz.Campground{
+				{ID: "upper", Name: "Upper Pines"},
+				{ID: "lower", Name: "Lower Pines"},
+			},
+		},
+	}
+
+	idx := NewIndex(nil, props)
+	if idx.Len() != 2 {
+		t.Fatalf("Len() = %d, want 2", idx.Len())
+	}
+
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"Upper Pines", []string{"/ca/yosemite/pines"}},
+		{"Joseph Grant", []string{"/ca/sj/grant"}},
+		{"Upper Pnes", []string{"/ca/yosemite/pines"}},
+		{"Pinecrest Park", []string{"/ca/yosemite/pines"}},
+		// "sadriver" is as short as "yosemite", so could be within a few edits of it
+		{"Sad River", []string{"/ca/yosemite/pines"}},
+		{"Hetch Hetchy Reservoir", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			name := mangle.Normalize(tt.in)
+			var got []string
+			for _, ip := range idx.candidates(name, variations(name)) {
+				got = append(got, ip.prop.ID)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("candidates(%q) = %v, want %v", tt.in, got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("candidates(%q) = %v, want %v", tt.in, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestIndexMatchesFullScan(t *testing.T) {
+	srcs, props, err := metadata.LoadAll()
+	if err != nil {
+		t.Fatalf("loadall: %v", err)
+	}
+
+	idx := NewIndex(srcs, props)
+	full := NewIndex(srcs, props)
+	full.fullScan = true
+
+	// Typos and names made only of common words are always checked, along with known names
+	tricky := []string{"Pinacles", "Trimer", "Colege", "Cottowood", "Creek Campground", "Sad River", "CA"}
+	names := []string{}
+	ids := []string{}
+	for id := range props {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		names = append(names, props[id].Name)
+		for _, cg := range props[id].Campgrounds {
+			names = append(names, cg.Name)
+		}
+	}
+
+	// Each full scan is slow, so only check a sample of known names in short mode
+	if testing.Short() {
+		sample := []string{}
+		for i := 0; i < len(names); i += 20 {
+			sample = append(sample, names[i])
+		}
+		names = sample
+	}
+	names = append(tricky, names...)
+
+	type mismatch struct {
+		name      string
+		got, want Match
+	}
+	work := make(chan string)
+	found := make(chan mismatch)
+	var wg sync.WaitGroup
+	for i := 0; i < runtime.GOMAXPROCS(0); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := range work {
+				r := campwiz.Result{Name: n}
+				got, want := findBestMatch(r, idx), findBestMatch(r, full)
+				if got.Score != want.Score || got.Campground != want.Campground || got.Detail != want.Detail {
+					found <- mismatch{name: n, got: got, want: want}
+				}
+			}
+		}()
+	}
+	go func() {
+		for _, n := range names {
+			work <- n
+		}
+		close(work)
+		wg.Wait()
+		close(found)
+	}()
+
+	for m := range found {
+		t.Errorf("findBestMatch(%q) = %s %q, full scan found %s %q", m.name, scoreNames[m.got.Score], m.got.Detail, scoreNames[m.want.Score], m.want.Detail)
+	}
+}
+
+// benchResults returns results named after known campgrounds, plus some that are not known at all
+func benchResults(props map[string]*campwiz.Property) []campwiz.Result {
+	rs := []campwiz.Result{{Name: "Sad River"}, {Name: "Campy Right Campground"}}
+	for _, p := range props {
+		for _, cg := range p.Campgrounds {
+			rs = append(rs, campwiz.Result{Name: cg.Name})
+		}
+		if len(rs) >= 50 {
+			break
+		}
+	}
+	return rs
+}
+
+func benchmarkAnnotate(b *testing.B, fullScan bool) {
+	srcs, props, err := metadata.LoadAll()
+	if err != nil {
+		b.Fatalf("loadall: %v", err)
+	}
+
+	idx := NewIndex(srcs, props)
+	idx.fullScan = fullScan
+	rs := benchResults(props)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		annotate(rs[i%len(rs)], idx)
+	}
+}
+
+func BenchmarkAnnotateIndex(b *testing.B) { benchmarkAnnotate(b, false) }
+
+func BenchmarkAnnotateFullScan(b *testing.B) { benchmarkAnnotate(b, true) }
@@ -0,0 +1,175 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/tstromberg/campwiz/pkg/metadata"
+	"k8s.io/klog/v2"
+)
+
+// maxReportedProblems is how many metadata problems to include in a reload error
+const maxReportedProblems = 5
+
+// Live holds the index used for searches, which may be replaced while searches are running
+type Live struct {
+	v atomic.Value
+}
+
+// NewLive returns a Live holding an initial index
+func NewLive(idx *Index) *Live {
+	l := &Live{}
+	l.Store(idx)
+	return l
+}
+
+// Index returns the current index. Searches should call it once, so that they see a consistent set of metadata.
+func (l *Live) Index() *Index {
+	idx, _ := l.v.Load().(*Index)
+	return idx
+}
+
+// Store replaces the current index
+func (l *Live) Store(idx *Index) {
+	l.v.Store(idx)
+}
+
+// Reloader rebuilds a Live index from metadata files, keeping the current index if the new files are invalid
+type Reloader struct {
+	paths []string
+	live  *Live
+
+	mu sync.Mutex
+	// stamps are the modification times and sizes of the metadata files at the last reload attempt
+	stamps map[string]string
+}
+
+// NewReloader returns a Reloader for metadata at paths, which must already be loaded into live
+func NewReloader(paths []string, live *Live) (*Reloader, error) {
+	paths = metadata.Resolve(paths)
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("bundled metadata can not be reloaded")
+	}
+
+	r := &Reloader{paths: paths, live: live}
+	stamps, err := r.stat()
+	if err != nil {
+		return nil, err
+	}
+	r.stamps = stamps
+	return r, nil
+}
+
+// stat returns a stamp for each metadata file, which changes if the file does
+func (r *Reloader) stat() (map[string]string, error) {
+	files, err := metadata.Files(r.paths)
+	if err != nil {
+		return nil, err
+	}
+
+	stamps := map[string]string{}
+	for _, f := range files {
+		fi, err := os.Stat(f)
+		if err != nil {
+			return nil, err
+		}
+		stamps[f] = fmt.Sprintf("%s/%d", fi.ModTime(), fi.Size())
+	}
+	return stamps, nil
+}
+
+// Changed returns true if any metadata file has been added, removed or modified since the last reload attempt
+func (r *Reloader) Changed() (bool, error) {
+	stamps, err := r.stat()
+	if err != nil {
+		return false, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(stamps) != len(r.stamps) {
+		return true, nil
+	}
+	for f, s := range stamps {
+		if r.stamps[f] != s {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Reload validates and loads the metadata files, replacing the live index if they are valid
+func (r *Reloader) Reload() (*Index, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Record the attempt even if it fails, so that invalid files are not retried until they change again
+	if stamps, err := r.stat(); err == nil {
+		r.stamps = stamps
+	}
+
+	ps, err := metadata.Lint(r.paths)
+	if err != nil {
+		return nil, fmt.Errorf("lint: %w", err)
+	}
+
+	if len(ps) > 0 {
+		msgs := []string{}
+		for i, p := range ps {
+			if i == maxReportedProblems {
+				msgs = append(msgs, fmt.Sprintf("and %d more", len(ps)-i))
+				break
+			}
+			msgs = append(msgs, p.String())
+		}
+		return nil, fmt.Errorf("%d problem(s) found: %s", len(ps), strings.Join(msgs, "; "))
+	}
+
+	srcs, props, err := metadata.Load(r.paths)
+	if err != nil {
+		return nil, fmt.Errorf("load: %w", err)
+	}
+	if len(props) == 0 {
+		return nil, fmt.Errorf("no properties found in %v", r.paths)
+	}
+
+	idx := NewIndex(srcs, props)
+	r.live.Store(idx)
+	return idx, nil
+}
+
+// Watch reloads metadata whenever it changes, checking every interval until ctx is done
+func (r *Reloader) Watch(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+
+		changed, err := r.Changed()
+		if err != nil {
+			klog.Errorf("metadata check: %v", err)
+			continue
+		}
+		if !changed {
+			continue
+		}
+
+		idx, err := r.Reload()
+		if err != nil {
+			klog.Errorf("metadata reload failed, keeping previous metadata: %v", err)
+			continue
+		}
+		klog.Infof("reloaded metadata: %d properties", idx.Len())
+	}
+}
@@ -0,0 +1,75 @@
+package search
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const liveProps = `
+properties:
+    - id: /ca/sj/grant
+      name: Joseph D. Grant County Park
+      campgrounds:
+        - id: grant
+          name: Joseph D. Grant County Park
+`
+
+func TestReloader(t *testing.T) {
+	dir, err := ioutil.TempDir("", "reloader")
+	if err != nil {
+		t.Fatalf("tempdir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "ca.yaml")
+	write := func(s string, mtime time.Time) {
+		if err := ioutil.WriteFile(path, []byte(s), 0o600); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		// Ensure each write is seen as a change, even on filesystems with coarse timestamps
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatalf("chtimes: %v", err)
+		}
+	}
+
+	start := time.Now().Add(-time.Hour)
+	write(liveProps, start)
+
+	orig := NewIndex(nil, nil)
+	live := NewLive(orig)
+	rl, err := NewReloader([]string{dir}, live)
+	if err != nil {
+		t.Fatalf("NewReloader: %v", err)
+	}
+
+	if changed, err := rl.Changed(); err != nil || changed {
+		t.Errorf("Changed() = %v, %v before any change", changed, err)
+	}
+
+	// Invalid metadata must not replace the live index
+	write(liveProps+"    - id: Not A Valid ID\n      name: Invalid\n      campgrounds: []\n", start.Add(time.Minute))
+	if changed, err := rl.Changed(); err != nil || !changed {
+		t.Errorf("Changed() = %v, %v after an invalid change", changed, err)
+	}
+	if _, err := rl.Reload(); err == nil {
+		t.Errorf("Reload() of invalid metadata returned no error")
+	}
+	if live.Index() != orig {
+		t.Errorf("invalid metadata replaced the live index")
+	}
+	if changed, _ := rl.Changed(); changed {
+		t.Errorf("Changed() = true after a failed reload of the same files")
+	}
+
+	write(liveProps, start.Add(2*time.Minute))
+	idx, err := rl.Reload()
+	if err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	if live.Index() != idx || idx.Len() != 1 {
+		t.Errorf("live index = %p with %d properties, want %p with 1", live.Index(), live.Index().Len(), idx)
+	}
+}
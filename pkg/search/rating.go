@@ -0,0 +1,65 @@
+package search
+
+import (
+	"sort"
+
+	"github.com/tstromberg/campwiz/pkg/campwiz"
+	"k8s.io/klog/v2"
+)
+
+// rate returns the per-source ratings of a campground, and their weighted average out of campwiz.RatingScale
+func rate(cg *campwiz.Campground, srcs map[string]campwiz.Source) ([]campwiz.SourceRating, float64) {
+	keys := []string{}
+	for k := range cg.Refs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	srs := []campwiz.SourceRating{}
+	total := 0.0
+	weights := 0.0
+
+	for _, k := range keys {
+		ref := cg.Refs[k]
+		if ref.Rating <= 0 {
+			continue
+		}
+
+		src, ok := srcs[k]
+		if !ok {
+			klog.V(1).Infof("%q refers to unknown source %q", cg.Name, k)
+			src.Name = k
+		}
+
+		// Sources without a known maximum are assumed to share our scale
+		max := src.RatingMax
+		if max <= 0 {
+			max = campwiz.RatingScale
+		}
+
+		weight := src.Weight
+		if weight <= 0 {
+			weight = 1
+		}
+
+		sr := campwiz.SourceRating{
+			Source:     k,
+			Name:       src.Name,
+			Desc:       src.RatingDesc,
+			Rating:     ref.Rating,
+			RatingMax:  max,
+			Normalized: ref.Rating / max * campwiz.RatingScale,
+			Weight:     weight,
+			Lists:      ref.Lists,
+		}
+
+		srs = append(srs, sr)
+		total += sr.Normalized * weight
+		weights += weight
+	}
+
+	if weights == 0 {
+		return srs, 0
+	}
+	return srs, total / weights
+}
@@ -0,0 +1,75 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tstromberg/campwiz/pkg/campwiz"
+)
+
+func TestRate(t *testing.T) {
+	srcs := map[string]campwiz.Source{
+		"cc":    {Name: "California Camping", RatingMax: 10, RatingDesc: "scenery"},
+		"stars": {Name: "Stars", RatingMax: 5, Weight: 3},
+	}
+
+	tests := []struct {
+		name   string
+		refs   map[string]*campwiz.Ref
+		want   []campwiz.SourceRating
+		rating float64
+	}{
+		{
+			name:   "no ratings",
+			refs:   map[string]*campwiz.Ref{"cc": {Name: "Unrated"}},
+			want:   []campwiz.SourceRating{},
+			rating: 0,
+		},
+		{
+			name: "single source",
+			refs: map[string]*campwiz.Ref{"cc": {Rating: 8}},
+			want: []campwiz.SourceRating{
+				{Source: "cc", Name: "California Camping", Desc: "scenery", Rating: 8, RatingMax: 10, Normalized: 8, Weight: 1},
+			},
+			rating: 8,
+		},
+		{
+			name: "weighted sources",
+			refs: map[string]*campwiz.Ref{"cc": {Rating: 8}, "stars": {Rating: 4}},
+			want: []campwiz.SourceRating{
+				{Source: "cc", Name: "California Camping", Desc: "scenery", Rating: 8, RatingMax: 10, Normalized: 8, Weight: 1},
+				{Source: "stars", Name: "Stars", Rating: 4, RatingMax: 5, Normalized: 8, Weight: 3},
+			},
+			rating: 8,
+		},
+		{
+			name: "uneven weights",
+			refs: map[string]*campwiz.Ref{"cc": {Rating: 4}, "stars": {Rating: 4}},
+			want: []campwiz.SourceRating{
+				{Source: "cc", Name: "California Camping", Desc: "scenery", Rating: 4, RatingMax: 10, Normalized: 4, Weight: 1},
+				{Source: "stars", Name: "Stars", Rating: 4, RatingMax: 5, Normalized: 8, Weight: 3},
+			},
+			rating: 7,
+		},
+		{
+			name: "unknown source",
+			refs: map[string]*campwiz.Ref{"mystery": {Rating: 6}},
+			want: []campwiz.SourceRating{
+				{Source: "mystery", Name: "mystery", Rating: 6, RatingMax: 10, Normalized: 6, Weight: 1},
+			},
+			rating: 6,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, rating := rate(&campwiz.Campground{Name: tt.name, Refs: tt.refs}, srcs)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("rate() mismatch (-want +got):\n%s", diff)
+			}
+			if rating != tt.rating {
+				t.Errorf("rate() = %.2f, want %.2f", rating, tt.rating)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package search
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/tstromberg/campwiz/pkg/campwiz"
+)
+
+// IDProposal is a suggested reservation ID mapping for a known campground
+type IDProposal struct {
+	PropertyID   string            `yaml:"property"`
+	CampgroundID string            `yaml:"campground"`
+	Name         string            `yaml:"name"`
+	Confidence   float64           `yaml:"confidence"`
+	ResIDs       map[string]string `yaml:"res_ids"`
+}
+
+// ResHost returns the reservation site host for a URL, as used for keys in Campground.ResIDs
+func ResHost(s string) string {
+	u, err := url.Parse(s)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+}
+
+// resKey returns a lookup key for a reservation ID at a host
+func resKey(host string, id string) string {
+	id = strings.TrimSpace(id)
+	if host == "" || id == "" {
+		return ""
+	}
+	return host + "/" + id
+}
+
+// resKeys returns the lookup keys a campground can be found by
+func resKeys(cg *campwiz.Campground) []string {
+	keys := []string{}
+	if k := resKey(ResHost(cg.ResURL), cg.ResID); k != "" {
+		keys = append(keys, k)
+	}
+	for host, id := range cg.ResIDs {
+		if k := resKey(strings.ToLower(host), id); k != "" {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// ProposeIDs suggests reservation ID mappings for campgrounds matched by name with at least minConfidence
+func ProposeIDs(rs []campwiz.Result, minConfidence float64) []IDProposal {
+	best := map[string]IDProposal{}
+
+	for _, r := range rs {
+		cg := r.KnownCampground
+		if cg == nil || r.MatchConfidence < minConfidence {
+			continue
+		}
+
+		host := ResHost(r.ResURL)
+		key := resKey(host, r.ResID)
+		if key == "" {
+			continue
+		}
+
+		// Already mapped: nothing to propose
+		known := false
+		for _, k := range resKeys(cg) {
+			if k == key {
+				known = true
+			}
+		}
+		if known {
+			continue
+		}
+
+		if p, ok := best[key]; ok && p.Confidence >= r.MatchConfidence {
+			continue
+		}
+
+		best[key] = IDProposal{
+			PropertyID:   cg.PropertyID,
+			CampgroundID: cg.ID,
+			Name:         r.Name,
+			Confidence:   r.MatchConfidence,
+			ResIDs:       map[string]string{host: strings.TrimSpace(r.ResID)},
+		}
+	}
+
+	ps := []IDProposal{}
+	for _, p := range best {
+		ps = append(ps, p)
+	}
+
+	sort.Slice(ps, func(i, j int) bool {
+		if ps[i].PropertyID != ps[j].PropertyID {
+			return ps[i].PropertyID < ps[j].PropertyID
+		}
+		if ps[i].CampgroundID != ps[j].CampgroundID {
+			return ps[i].CampgroundID < ps[j].CampgroundID
+		}
+		return ps[i].Name < ps[j].Name
+	})
+	return ps
+}
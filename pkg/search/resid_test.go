@@ -0,0 +1,93 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tstromberg/campwiz/pkg/campwiz"
+)
+
+func TestResHost(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"https://www.reservecalifornia.com/", "reservecalifornia.com"},
+		{"http://WWW.Recreation.gov", "recreation.gov"},
+		{"https://gooutsideandplay.org/index.asp", "gooutsideandplay.org"},
+		{"not a url", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := ResHost(tt.in); got != tt.want {
+			t.Errorf("ResHost(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFindBestMatchResID(t *testing.T) {
+	mapped := &campwiz.Campground{
+		ID:     "lake",
+		Name:   "Lake Campground",
+		ResIDs: map[string]string{"reservecalifornia.com": "718"},
+	}
+	legacy := &campwiz.Campground{
+		ID:     "pines",
+		Name:   "Upper Pines",
+		ResURL: "http://www.recreation.gov",
+		ResID:  "232447",
+	}
+	props := map[string]*campwiz.Property{
+		"/ca/tahoe/lake":     {ID: "/ca/tahoe/lake", Name: "Tahoe", Campgrounds: []*campwiz.Campground{mapped}},
+		"/ca/yosemite/pines": {ID: "/ca/yosemite/pines", Name: "Yosemite", Campgrounds: []*campwiz.Campground{legacy}},
+	}
+	idx := NewIndex(nil, props)
+
+	tests := []struct {
+		name  string
+		r     campwiz.Result
+		score int
+		id    string
+	}{
+		{"res_ids", campwiz.Result{Name: "Something Else", ResURL: "https://www.reservecalifornia.com/", ResID: "718"}, SiteID, "lake"},
+		{"padded id", campwiz.Result{Name: "Something Else", ResURL: "https://www.reservecalifornia.com/", ResID: " 718"}, SiteID, "lake"},
+		{"res_url and res_id", campwiz.Result{Name: "Pines", ResURL: "https://www.recreation.gov/", ResID: "232447"}, SiteID, "pines"},
+		{"other host", campwiz.Result{Name: "Lake Campground", ResURL: "https://www.recreation.gov/", ResID: "718"}, NameMatch, "lake"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := findBestMatch(tt.r, idx)
+			if got.Score != tt.score {
+				t.Errorf("got score %d %q, want %d %q: %+v", got.Score, scoreNames[got.Score], tt.score, scoreNames[tt.score], got)
+			}
+			if got.Campground == nil || got.Campground.ID != tt.id {
+				t.Fatalf("got campground %+v, expected %q", got.Campground, tt.id)
+			}
+		})
+	}
+}
+
+func TestProposeIDs(t *testing.T) {
+	mapped := &campwiz.Campground{ID: "lake", PropertyID: "/ca/tahoe/lake", ResIDs: map[string]string{"reservecalifornia.com": "718"}}
+	pines := &campwiz.Campground{ID: "pines", PropertyID: "/ca/yosemite/pines"}
+	flat := &campwiz.Campground{ID: "flat", PropertyID: "/ca/sj/flat"}
+
+	rs := []campwiz.Result{
+		{Name: "Lake", ResURL: "https://www.reservecalifornia.com/", ResID: "718", KnownCampground: mapped, MatchConfidence: 1},
+		{Name: "Upper Pines", ResURL: "https://www.recreation.gov/", ResID: "232447", KnownCampground: pines, MatchConfidence: 0.93},
+		{Name: "Pines Again", ResURL: "https://www.recreation.gov/", ResID: "232447", KnownCampground: flat, MatchConfidence: 0.91},
+		{Name: "Flat", ResURL: "https://www.reservecalifornia.com/", ResID: " 9", KnownCampground: flat, MatchConfidence: 0.5},
+		{Name: "Unknown", ResURL: "https://www.reservecalifornia.com/", ResID: "10"},
+	}
+
+	want := []IDProposal{
+		{PropertyID: "/ca/yosemite/pines", CampgroundID: "pines", Name: "Upper Pines", Confidence: 0.93, ResIDs: map[string]string{"recreation.gov": "232447"}},
+	}
+
+	got := ProposeIDs(rs, 0.9)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ProposeIDs() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,169 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tstromberg/campwiz/pkg/backend"
+	"github.com/tstromberg/campwiz/pkg/backend/fake"
+	"github.com/tstromberg/campwiz/pkg/campwiz"
+)
+
+// memStore is an in-memory cache.Store
+type memStore map[string][]byte
+
+func (m memStore) Read(key string) ([]byte, error) {
+	bs, ok := m[key]
+	if !ok {
+		return nil, fmt.Errorf("%q not found", key)
+	}
+	return bs, nil
+}
+
+func (m memStore) Write(key string, bs []byte) error {
+	m[key] = bs
+	return nil
+}
+
+func (m memStore) Delete(key string) error {
+	delete(m, key)
+	return nil
+}
+
+func TestRunFake(t *testing.T) {
+	s := fake.New(fake.Data{
+		RAmerica: []fake.Campground{
+			{ID: "STAN_1040013", Name: "Frank Raines Regional Park", Miles: 62.9},
+			{ID: "PRCG_1060800", Name: "Clear Lake Campground", Miles: 81.4, Dates: []string{"2021-03-13"}},
+		},
+		RCalifornia: []fake.Campground{
+			{ID: "718", Name: "Big Basin Redwoods SP", Miles: 28, Lat: 37.17, Lon: -122.22},
+			{ID: "661", Name: "Pfeiffer Big Sur SP", Miles: 112, Lat: 36.25, Lon: -121.78},
+		},
+		SCC: []fake.Campground{
+			{Name: "Coyote Lake", Kind: "Camping - RV/Electric", Sites: []string{"6RV", "9RV"}},
+		},
+		SMC: []fake.Campground{
+			{ID: "coyote-point", Sites: []string{"1", "2"}},
+		},
+	})
+	defer s.Close()
+	s.Fail("/sanmateo/campsites/feed.html", http.StatusServiceUnavailable)
+
+	origNew := newProvider
+	defer func() { newProvider = origNew }()
+	newProvider = func(c backend.Config) (backend.Provider, error) {
+		c.BaseURL = s.URL
+		return backend.New(c)
+	}
+
+	props := map[string]*campwiz.Property{
+		"/ca/santa_cruz/big_basin": {
+			ID:   "/ca/santa_cruz/big_basin",
+			Name: "Big Basin Redwoods State Park",
+			Campgrounds: []*campwiz.Campground{{
+				ID:   "big_basin",
+				Name: "Big Basin Redwoods SP",
+				Refs: map[string]*campwiz.Ref{"test": {Rating: 4}},
+			}},
+		},
+	}
+	idx := NewIndex(map[string]campwiz.Source{"test": {Name: "Test", RatingMax: 5}}, props)
+
+	date, err := time.Parse("2006-01-02", "2021-03-12")
+	if err != nil {
+		t.Fatalf("time parse: %v", err)
+	}
+	q := campwiz.Query{
+		Dates:       []time.Time{date},
+		StayLength:  2,
+		Lat:         37.2,
+		Lon:         -122.1,
+		MaxDistance: 100,
+	}
+
+	got, errs := Run(context.Background(), []string{"ramerica", "rcalifornia", "scc", "smc"}, q, memStore{}, idx)
+
+	// Rated results sort first
+	gotNames := []string{}
+	for _, r := range got {
+		gotNames = append(gotNames, r.Name)
+	}
+	want := []string{"Big Basin Redwoods SP", "Frank Raines Regional Park", "Coyote Lake"}
+	if diff := cmp.Diff(want, gotNames); diff != "" {
+		t.Errorf("Run() mismatch (-want +got):\n%s", diff)
+	}
+	if len(got) > 0 && got[0].Rating != 8 {
+		t.Errorf("Run() rating for %q = %.1f, want 8", got[0].Name, got[0].Rating)
+	}
+
+	if len(errs) != 1 || !strings.HasPrefix(errs[0].Error(), "smc list") {
+		t.Errorf("Run() errors = %v, want a single smc failure", errs)
+	}
+}
+
+func TestRunFakeEquipment(t *testing.T) {
+	s := fake.New(fake.Data{
+		RCalifornia: []fake.Campground{
+			{ID: "718", Name: "Big Basin Redwoods SP", Miles: 28, Lat: 37.17, Lon: -122.22},
+		},
+		SMC: []fake.Campground{
+			{ID: "coyote-point", Kind: "RV/Electric", Sites: []string{"1", "2"}, MaxRV: 36},
+			{ID: "huddart-park", Sites: []string{"7"}},
+		},
+	})
+	defer s.Close()
+
+	origNew := newProvider
+	defer func() { newProvider = origNew }()
+	newProvider = func(c backend.Config) (backend.Provider, error) {
+		c.BaseURL = s.URL
+		return backend.New(c)
+	}
+
+	date, err := time.Parse("2006-01-02", "2021-03-12")
+	if err != nil {
+		t.Fatalf("time parse: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		length int
+		want   []string
+	}{
+		{"30ft rv", 30, []string{"Big Basin Redwoods SP", "Coyote Point"}},
+		{"40ft rv", 40, []string{"Big Basin Redwoods SP"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := campwiz.Query{
+				Dates:         []time.Time{date},
+				StayLength:    2,
+				Lat:           37.2,
+				Lon:           -122.1,
+				MaxDistance:   100,
+				Equipment:     campwiz.EquipmentRV,
+				VehicleLength: tt.length,
+			}
+
+			got, errs := Run(context.Background(), []string{"rcalifornia", "smc"}, q, memStore{}, NewIndex(nil, nil))
+			if len(errs) > 0 {
+				t.Fatalf("Run() errors: %v", errs)
+			}
+
+			gotNames := []string{}
+			for _, r := range got {
+				gotNames = append(gotNames, r.Name)
+			}
+			if diff := cmp.Diff(tt.want, gotNames); diff != "" {
+				t.Errorf("Run() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -1,8 +1,10 @@
 package search
 
 import (
+	"context"
 	"fmt"
 	"sort"
+	"time"
 
 	"github.com/tstromberg/campwiz/pkg/backend"
 	"github.com/tstromberg/campwiz/pkg/cache"
@@ -10,46 +12,189 @@ import (
 	"k8s.io/klog"
 )
 
-var DefaultProviders = []string{"ramerica", "rcalifornia", "scc", "smc"}
+var (
+	// DefaultProviders includes both ReserveCalifornia backends: rcalifornia lists prices and vehicle lengths,
+	// while rcaliforniaAdv lists availability by unit type. Their results are merged by place.
+	DefaultProviders = []string{"ramerica", "rcalifornia", "rcaliforniaAdv", "scc", "smc"}
+
+	// maxWorkers is the maximum number of providers to query at once
+	maxWorkers = 4
+
+	// providerTimeout is how long to wait for a single provider to return results
+	providerTimeout = 90 * time.Second
+
+	// deadlineGrace is how long to wait for a provider to return partial results once its context is done
+	deadlineGrace = 2 * time.Second
+
+	// newProvider returns a backend provider, overridden in tests
+	newProvider = backend.New
+)
 
 // Run is a one-stop query shop: talks to backends, annotates, provides filtering
-func Run(providers []string, q campwiz.Query, cs cache.Store, props map[string]*campwiz.Property) ([]campwiz.Result, []error) {
-	rs, errs := unfiltered(providers, q, cs)
+func Run(ctx context.Context, providers []string, q campwiz.Query, cs cache.Store, idx *Index) ([]campwiz.Result, []error) {
+	// Providers only understand concrete arrival dates
+	q.Dates = q.ArrivalDates()
+	rs, errs := unfiltered(ctx, providers, q, cs)
 
 	as := []campwiz.Result{}
 	for _, r := range rs {
-		as = append(as, annotate(r, props))
+		as = append(as, annotate(r, idx))
 	}
 
 	fs := filter(q, as)
 
-	sort.Slice(fs, func(i, j int) bool { return fs[i].Rating > fs[j].Rating })
+	sort.SliceStable(fs, func(i, j int) bool { return fs[i].Rating > fs[j].Rating })
+	if q.SortBy == campwiz.SortPrice {
+		sortByPrice(fs)
+	}
 	return fs, errs
 }
 
+// sortByPrice orders results by their lowest nightly price, keeping those with unknown prices at the end
+func sortByPrice(rs []campwiz.Result) {
+	sort.SliceStable(rs, func(i, j int) bool {
+		pi, pj := rs[i].LowestPrice(), rs[j].LowestPrice()
+		if pi == 0 || pj == 0 {
+			return pj == 0 && pi != 0
+		}
+		return pi < pj
+	})
+}
+
+// providerResult is the outcome of querying a single provider
+type providerResult struct {
+	results []campwiz.Result
+	err     error
+}
+
 // unfiltered searches for results across providers, without filters
-func unfiltered(providers []string, q campwiz.Query, cs cache.Store) ([]campwiz.Result, []error) {
+func unfiltered(ctx context.Context, providers []string, q campwiz.Query, cs cache.Store) ([]campwiz.Result, []error) {
 	klog.V(1).Infof("search campwiz.Query: %+v", q)
 
+	// Each provider writes to its own slot, so that the output order matches the input order
+	out := make([]providerResult, len(providers))
+	sem := make(chan struct{}, maxWorkers)
+	done := make(chan int, len(providers))
+
+	for i, pname := range providers {
+		go func(i int, pname string) {
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			out[i] = list(ctx, pname, q, cs)
+			done <- i
+		}(i, pname)
+	}
+
+	for range providers {
+		<-done
+	}
+
 	results := []campwiz.Result{}
 	errs := []error{}
+	for _, pr := range out {
+		if pr.err != nil {
+			errs = append(errs, pr.err)
+		}
+		results = append(results, pr.results...)
+	}
 
-	// There is an opportunity to parallelize this with channels if anyone is keen to do so
-	for _, pname := range providers {
-		p, err := backend.New(backend.Config{Type: pname, Store: cs})
-		if err != nil {
-			errs = append(errs, fmt.Errorf("%s init: %v", pname, err))
+	return dedupe(results), errs
+}
+
+// dedupe merges results for the same reservation site and ID, which several providers may return.
+// The first result is kept, with availability from the others added or filling in what it lacks.
+func dedupe(rs []campwiz.Result) []campwiz.Result {
+	seen := map[string]int{}
+	out := []campwiz.Result{}
+
+	for _, r := range rs {
+		key := resKey(ResHost(r.ResURL), r.ResID)
+		if key == "" {
+			out = append(out, r)
 			continue
 		}
 
-		prs, err := p.List(q)
-		if err != nil {
-			errs = append(errs, fmt.Errorf("%s list: %v", pname, err))
+		i, ok := seen[key]
+		if !ok {
+			seen[key] = len(out)
+			out = append(out, r)
 			continue
 		}
 
-		results = append(results, prs...)
+		klog.V(1).Infof("merging duplicate result for %s: %q", key, r.Name)
+		out[i].Availability = mergeAvailability(out[i].Availability, r.Availability)
 	}
+	return out
+}
+
+// mergeAvailability adds availability entries for new dates and kinds, and fills in unknown details of existing ones
+func mergeAvailability(as []campwiz.Availability, more []campwiz.Availability) []campwiz.Availability {
+	merged := append([]campwiz.Availability{}, as...)
 
-	return results, errs
+	for _, m := range more {
+		found := false
+		for i := range merged {
+			a := &merged[i]
+			if !a.Date.Equal(m.Date) || a.Kind != m.Kind {
+				continue
+			}
+			found = true
+			if a.SpotCount == 0 {
+				a.SpotCount = m.SpotCount
+			}
+			if len(a.Sites) == 0 {
+				a.Sites = m.Sites
+			}
+			if a.PricePerNight == 0 {
+				a.PricePerNight, a.TotalPrice = m.PricePerNight, m.TotalPrice
+			}
+			break
+		}
+		if !found {
+			merged = append(merged, m)
+		}
+	}
+	return merged
+}
+
+// list queries a single provider, giving up after providerTimeout
+func list(ctx context.Context, pname string, q campwiz.Query, cs cache.Store) providerResult {
+	p, err := newProvider(backend.Config{Type: pname, Store: cs})
+	if err != nil {
+		return providerResult{err: fmt.Errorf("%s init: %v", pname, err)}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, providerTimeout)
+	defer cancel()
+
+	c := make(chan providerResult, 1)
+	start := time.Now()
+
+	go func() {
+		prs, err := p.List(ctx, q)
+		if err != nil {
+			err = fmt.Errorf("%s list: %v", pname, err)
+		}
+		c <- providerResult{results: prs, err: err}
+	}()
+
+	// Providers should return promptly once ctx is done, but do not wait long on those that don't.
+	select {
+	case pr := <-c:
+		klog.Infof("%s returned %d results in %s", pname, len(pr.results), time.Since(start))
+		return pr
+	case <-ctx.Done():
+	}
+
+	// Give the provider a moment to hand back any partial results it gathered before the deadline
+	t := time.NewTimer(deadlineGrace)
+	defer t.Stop()
+
+	select {
+	case pr := <-c:
+		klog.Infof("%s returned %d results at its deadline, after %s", pname, len(pr.results), time.Since(start))
+		return pr
+	case <-t.C:
+		return providerResult{err: fmt.Errorf("%s list: %v after %s", pname, ctx.Err(), time.Since(start).Round(time.Millisecond))}
+	}
 }
@@ -0,0 +1,198 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tstromberg/campwiz/pkg/backend"
+	"github.com/tstromberg/campwiz/pkg/campwiz"
+)
+
+type fakeProvider struct {
+	name  string
+	delay time.Duration
+	err   error
+}
+
+func (p *fakeProvider) Name() string {
+	return p.name
+}
+
+func (p *fakeProvider) List(ctx context.Context, q campwiz.Query) ([]campwiz.Result, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(p.delay):
+	}
+	return []campwiz.Result{{Name: p.name + " 1"}, {Name: p.name + " 2"}}, p.err
+}
+
+// partialProvider returns what it has found so far once ctx is done, or nothing if it ignores ctx
+type partialProvider struct {
+	name   string
+	ignore bool
+}
+
+func (p *partialProvider) Name() string {
+	return p.name
+}
+
+func (p *partialProvider) List(ctx context.Context, q campwiz.Query) ([]campwiz.Result, error) {
+	if p.ignore {
+		time.Sleep(time.Hour)
+	}
+	<-ctx.Done()
+	return []campwiz.Result{{Name: p.name + " 1"}}, ctx.Err()
+}
+
+func TestUnfiltered(t *testing.T) {
+	fakes := map[string]*fakeProvider{
+		"slow":   {name: "slow", delay: 50 * time.Millisecond},
+		"fast":   {name: "fast"},
+		"broken": {name: "broken", err: fmt.Errorf("session expired")},
+		"stuck":  {name: "stuck", delay: time.Hour},
+	}
+
+	origNew, origTimeout := newProvider, providerTimeout
+	defer func() { newProvider, providerTimeout = origNew, origTimeout }()
+
+	providerTimeout = 250 * time.Millisecond
+	newProvider = func(c backend.Config) (backend.Provider, error) {
+		p, ok := fakes[c.Type]
+		if !ok {
+			return nil, fmt.Errorf("unknown backend type: %q", c.Type)
+		}
+		return p, nil
+	}
+
+	got, errs := unfiltered(context.Background(), []string{"slow", "stuck", "missing", "fast", "broken"}, campwiz.Query{}, nil)
+
+	gotNames := []string{}
+	for _, r := range got {
+		gotNames = append(gotNames, r.Name)
+	}
+
+	want := []string{"slow 1", "slow 2", "fast 1", "fast 2", "broken 1", "broken 2"}
+	if diff := cmp.Diff(want, gotNames); diff != "" {
+		t.Errorf("unfiltered() mismatch (-want +got):\n%s", diff)
+	}
+
+	gotErrs := []string{}
+	for _, e := range errs {
+		gotErrs = append(gotErrs, strings.Split(e.Error(), ":")[0])
+	}
+
+	wantErrs := []string{"stuck list", "missing init", "broken list"}
+	if diff := cmp.Diff(wantErrs, gotErrs); diff != "" {
+		t.Errorf("unfiltered() errors mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestUnfilteredPartial(t *testing.T) {
+	fakes := map[string]*partialProvider{
+		"partial": {name: "partial"},
+		"ignored": {name: "ignored", ignore: true},
+	}
+
+	origNew, origTimeout, origGrace := newProvider, providerTimeout, deadlineGrace
+	defer func() { newProvider, providerTimeout, deadlineGrace = origNew, origTimeout, origGrace }()
+
+	providerTimeout = 50 * time.Millisecond
+	deadlineGrace = 250 * time.Millisecond
+	newProvider = func(c backend.Config) (backend.Provider, error) {
+		return fakes[c.Type], nil
+	}
+
+	got, errs := unfiltered(context.Background(), []string{"partial", "ignored"}, campwiz.Query{}, nil)
+
+	gotNames := []string{}
+	for _, r := range got {
+		gotNames = append(gotNames, r.Name)
+	}
+	if diff := cmp.Diff([]string{"partial 1"}, gotNames); diff != "" {
+		t.Errorf("unfiltered() mismatch (-want +got):\n%s", diff)
+	}
+
+	gotErrs := []string{}
+	for _, e := range errs {
+		gotErrs = append(gotErrs, strings.Split(e.Error(), ":")[0])
+	}
+	if diff := cmp.Diff([]string{"partial list", "ignored list"}, gotErrs); diff != "" {
+		t.Errorf("unfiltered() errors mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestUnfilteredCanceled(t *testing.T) {
+	origNew := newProvider
+	defer func() { newProvider = origNew }()
+
+	newProvider = func(c backend.Config) (backend.Provider, error) {
+		return &fakeProvider{name: c.Type, delay: time.Hour}, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	got, errs := unfiltered(ctx, []string{"a", "b"}, campwiz.Query{}, nil)
+	if len(got) > 0 {
+		t.Errorf("got %d results, want none", len(got))
+	}
+	if len(errs) != 2 {
+		t.Errorf("got errors %v, want 2", errs)
+	}
+}
+
+func TestSortByPrice(t *testing.T) {
+	rs := []campwiz.Result{
+		{Name: "unknown", Availability: []campwiz.Availability{{Kind: campwiz.Tent}}},
+		{Name: "pricey", Availability: []campwiz.Availability{{PricePerNight: 90}, {PricePerNight: 60}}},
+		{Name: "cheap", Availability: []campwiz.Availability{{PricePerNight: 25}, {}}},
+		{Name: "also unknown"},
+		{Name: "middle", Availability: []campwiz.Availability{{PricePerNight: 45}}},
+	}
+
+	sortByPrice(rs)
+
+	got := []string{}
+	for _, r := range rs {
+		got = append(got, r.Name)
+	}
+	want := []string{"cheap", "middle", "pricey", "unknown", "also unknown"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("sortByPrice() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestDedupe(t *testing.T) {
+	date := time.Date(2021, 3, 5, 0, 0, 0, 0, time.UTC)
+	rc := "https://www.reservecalifornia.com/"
+
+	rs := []campwiz.Result{
+		{Name: "Big Basin", ResURL: rc, ResID: "718", Availability: []campwiz.Availability{
+			{Kind: campwiz.Tent, Date: date, PricePerNight: 35, TotalPrice: 70},
+		}},
+		{Name: "Unknown Place"},
+		{Name: "Big Basin Redwoods SP", ResURL: rc, ResID: "718", Availability: []campwiz.Availability{
+			{Kind: campwiz.Tent, Date: date, SpotCount: 3},
+			{Kind: campwiz.RV, Date: date, SpotCount: 1},
+		}},
+		{Name: "Other Place"},
+	}
+
+	want := []campwiz.Result{
+		{Name: "Big Basin", ResURL: rc, ResID: "718", Availability: []campwiz.Availability{
+			{Kind: campwiz.Tent, Date: date, SpotCount: 3, PricePerNight: 35, TotalPrice: 70},
+			{Kind: campwiz.RV, Date: date, SpotCount: 1},
+		}},
+		{Name: "Unknown Place"},
+		{Name: "Other Place"},
+	}
+
+	if diff := cmp.Diff(want, dedupe(rs)); diff != "" {
+		t.Errorf("dedupe() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,50 @@
+package site
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"k8s.io/klog/v2"
+)
+
+// authorized returns true if a request presents the admin token as a bearer token
+func (h *Handlers) authorized(r *http.Request) bool {
+	if h.c.AdminToken == "" {
+		return false
+	}
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return subtle.ConstantTimeCompare([]byte(token), []byte(h.c.AdminToken)) == 1
+}
+
+// Reload validates and reloads metadata, keeping the current metadata if the new files are invalid
+func (h *Handlers) Reload() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "reload requires POST", http.StatusMethodNotAllowed)
+			return
+		}
+
+		if !h.authorized(r) {
+			klog.Warningf("unauthorized reload attempt from %s", r.RemoteAddr)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		if h.c.Reloader == nil {
+			http.Error(w, "metadata reloading is not configured", http.StatusNotImplemented)
+			return
+		}
+
+		idx, err := h.c.Reloader.Reload()
+		if err != nil {
+			klog.Errorf("metadata reload failed, keeping previous metadata: %v", err)
+			http.Error(w, fmt.Sprintf("reload failed, keeping previous metadata: %v", err), http.StatusUnprocessableEntity)
+			return
+		}
+
+		klog.Infof("reloaded metadata: %d properties", idx.Len())
+		fmt.Fprintf(w, "reloaded %d properties\n", idx.Len())
+	}
+}
@@ -0,0 +1,113 @@
+package site
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/tstromberg/campwiz/pkg/render"
+	"k8s.io/klog/v2"
+)
+
+// apiError is returned to API clients when a request can not be served
+type apiError struct {
+	Error string `json:"error"`
+}
+
+// apiSearch runs the search described by an API request. Errors are the fault of the request.
+func (h *Handlers) apiSearch(r *http.Request) (render.Context, error) {
+	q, err := h.query(r)
+	if err != nil {
+		return render.Context{}, err
+	}
+
+	q.Lat = getFloat(r.URL, "lat", q.Lat)
+	q.Lon = getFloat(r.URL, "lon", q.Lon)
+	q.Keywords = getStrs(r.URL, "keywords", nil)
+
+	providers, err := h.providers(getStrs(r.URL, "providers", nil))
+	if err != nil {
+		return render.Context{}, err
+	}
+
+	if len(q.ArrivalDates()) == 0 {
+		return render.Context{}, fmt.Errorf("at least one date is required")
+	}
+
+	idx := h.c.Index.Index()
+	rs, errs := runSearch(r.Context(), providers, q, h.c.Cache, idx)
+	if len(errs) > 0 {
+		klog.Errorf("search errors: %v", errs)
+	}
+
+	return render.Context{
+		Query:   q,
+		Sources: idx.Sources(),
+		Results: rs,
+		Errors:  errs,
+	}, nil
+}
+
+// SearchAPI returns search results as JSON
+func (h *Handlers) SearchAPI() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		klog.Infof("Incoming API request: %+v", r)
+		c, err := h.apiSearch(r)
+		if err != nil {
+			h.apiError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := render.JSON(w, c); err != nil {
+			klog.Errorf("encode: %v", err)
+		}
+	}
+}
+
+// SearchICS returns search results as a subscribable iCalendar feed
+func (h *Handlers) SearchICS() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		klog.Infof("Incoming iCalendar request: %+v", r)
+		c, err := h.apiSearch(r)
+		if err != nil {
+			h.apiError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+		w.Header().Set("Content-Disposition", `inline; filename="campwiz.ics"`)
+		if err := render.ICal(w, c); err != nil {
+			klog.Errorf("encode: %v", err)
+		}
+	}
+}
+
+// providers returns the providers requested, which must be a subset of those configured
+func (h *Handlers) providers(want []string) ([]string, error) {
+	if len(want) == 0 {
+		return h.c.Providers, nil
+	}
+
+	allowed := map[string]bool{}
+	for _, p := range h.c.Providers {
+		allowed[p] = true
+	}
+
+	for _, p := range want {
+		if !allowed[p] {
+			return nil, fmt.Errorf("provider %q is not enabled, choose from: %v", p, h.c.Providers)
+		}
+	}
+	return want, nil
+}
+
+// apiError writes an error as JSON
+func (h *Handlers) apiError(w http.ResponseWriter, code int, err error) {
+	klog.Errorf("API error %d: %v", code, err)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(apiError{Error: err.Error()}); err != nil {
+		klog.Errorf("encode: %v", err)
+	}
+}
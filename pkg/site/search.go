@@ -1,12 +1,14 @@
 package site
 
 import (
+	"fmt"
+	"html/template"
 	"io/ioutil"
 	"net/http"
 	"net/url"
 	"path/filepath"
 	"strconv"
-	"text/template"
+	"strings"
 	"time"
 
 	"github.com/tstromberg/campwiz/pkg/campwiz"
@@ -15,15 +17,35 @@ import (
 	"k8s.io/klog/v2"
 )
 
+// maxDateWindow is the longest window of arrival dates a single request may search
+var maxDateWindow = 92 * 24 * time.Hour
+
+// runSearch queries providers for results, overridden in tests
+var runSearch = search.Run
+
 type templateContext struct {
-	Query   campwiz.Query
-	Results []campwiz.Result
-	Sources map[string]campwiz.Source
-	Errors  []error
+	Query    campwiz.Query
+	Results  []campwiz.Result
+	Weekends []campwiz.Weekend
+	Sources  map[string]campwiz.Source
+	Errors   []error
 
 	Today      time.Time
 	SelectDate time.Time
 	Version    string
+	// CalendarURL is the calendar subscription link for the search, re-encoded from the parsed request
+	CalendarURL template.URL
+
+	SiteKindOptions []option
+	FeatureOptions  []option
+	WeekdayOptions  []option
+}
+
+// option is a checkbox within the search form
+type option struct {
+	Name    string
+	Label   string
+	Checked bool
 }
 
 func futureFriday() time.Time {
@@ -32,36 +54,144 @@ func futureFriday() time.Time {
 	return try.Add(time.Duration(offset) * 24 * time.Hour)
 }
 
+// query parses the search parameters shared by the HTML and API handlers
+func (h *Handlers) query(r *http.Request) (campwiz.Query, error) {
+	q := campwiz.Query{
+		Lon:         h.c.Longitude,
+		Lat:         h.c.Latitude,
+		StayLength:  getInt(r.URL, "nights", 2),
+		MaxDistance: getInt(r.URL, "distance", 100),
+		MinRating:   getFloat(r.URL, "min_rating", 0.0),
+		Keywords:    []string{getStr(r.URL, "keywords", "")},
+		MaxPrice:    getFloat(r.URL, "max_price", 0.0),
+
+		PartySize:     getInt(r.URL, "party_size", 0),
+		VehicleLength: getInt(r.URL, "vehicle_length", 0),
+	}
+
+	for _, ds := range r.URL.Query()["dates"] {
+		t, err := time.Parse("2006-01-02", ds)
+		if err != nil {
+			return q, err
+		}
+		q.Dates = append(q.Dates, t)
+	}
+
+	var err error
+	if ds := getStr(r.URL, "from", ""); ds != "" {
+		q.From, err = time.Parse("2006-01-02", ds)
+		if err != nil {
+			return q, err
+		}
+	}
+
+	if ds := getStr(r.URL, "until", ""); ds != "" {
+		q.Until, err = time.Parse("2006-01-02", ds)
+		if err != nil {
+			return q, err
+		}
+
+		// The search form only has a single date input, which starts the window
+		if q.From.IsZero() && len(q.Dates) == 1 {
+			q.From = q.Dates[0]
+			q.Dates = nil
+		}
+	}
+
+	if !q.From.IsZero() && q.Until.Sub(q.From) > maxDateWindow {
+		return q, fmt.Errorf("date window may not exceed %.0f days", maxDateWindow.Hours()/24)
+	}
+
+	q.Weekdays, err = campwiz.ParseWeekdays(getStrs(r.URL, "weekdays", nil))
+	if err != nil {
+		return q, err
+	}
+
+	q.SiteKinds, err = campwiz.ParseSiteKinds(getStrs(r.URL, "site_kinds", nil))
+	if err != nil {
+		return q, err
+	}
+
+	q.Features, err = campwiz.ParseFeatures(getStrs(r.URL, "features", nil))
+	if err != nil {
+		return q, err
+	}
+
+	q.SortBy, err = campwiz.ParseSortBy(getStr(r.URL, "sort", campwiz.SortRating))
+	if err != nil {
+		return q, err
+	}
+
+	q.Equipment, err = campwiz.ParseEquipment(getStr(r.URL, "equipment", ""))
+	if err != nil {
+		return q, err
+	}
+	return q, nil
+}
+
+// formOptions returns the site kind and feature checkboxes for a query
+func formOptions(q campwiz.Query) ([]option, []option) {
+	kinds := map[campwiz.SiteKind]bool{}
+	for _, k := range q.SiteKinds {
+		kinds[k] = true
+	}
+
+	features := map[int]bool{}
+	for _, f := range q.Features {
+		features[f] = true
+	}
+
+	var kos []option
+	for _, n := range campwiz.SiteKindList() {
+		k := campwiz.SiteKindNames[n]
+		kos = append(kos, option{Name: n, Label: string(k) + " " + strings.ReplaceAll(n, "_", " "), Checked: kinds[k]})
+	}
+
+	var fos []option
+	for _, n := range campwiz.FeatureList() {
+		fos = append(fos, option{Name: n, Label: strings.ReplaceAll(n, "_", " "), Checked: features[campwiz.FeatureNames[n]]})
+	}
+	return kos, fos
+}
+
+// weekdayOptions returns the day of week checkboxes for a query
+func weekdayOptions(q campwiz.Query) []option {
+	want := map[time.Weekday]bool{}
+	for _, w := range q.Weekdays {
+		want[w] = true
+	}
+
+	var wos []option
+	for i := time.Sunday; i <= time.Saturday; i++ {
+		name := strings.ToLower(i.String()[0:3])
+		wos = append(wos, option{Name: name, Label: i.String()[0:3], Checked: want[i]})
+	}
+	return wos
+}
+
 // Search returns search results
 func (h *Handlers) Search() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		klog.Infof("Incoming request: %+v", r)
-		q := campwiz.Query{
-			Lon:         h.c.Longitude,
-			Lat:         h.c.Latitude,
-			StayLength:  getInt(r.URL, "nights", 2),
-			MaxDistance: getInt(r.URL, "distance", 100),
-			MinRating:   getFloat(r.URL, "min_rating", 0.0),
-			Keywords:    []string{getStr(r.URL, "keywords", "")},
+		q, err := h.query(r)
+		if err != nil {
+			h.error(w, err)
+			return
 		}
 
 		selectDate := futureFriday()
-
-		for _, ds := range r.URL.Query()["dates"] {
-			t, err := time.Parse("2006-01-02", ds)
-			if err != nil {
-				h.error(w, err)
-				return
-			}
-			q.Dates = append(q.Dates, t)
-			selectDate = t
+		if !q.From.IsZero() {
+			selectDate = q.From
+		} else if len(q.Dates) > 0 {
+			selectDate = q.Dates[len(q.Dates)-1]
 		}
 
 		var rs []campwiz.Result
 		var errs []error
+		idx := h.c.Index.Index()
 
-		if len(q.Dates) > 0 {
-			rs, errs = search.Run(h.c.Providers, q, h.c.Cache, h.c.Properties)
+		if len(q.ArrivalDates()) > 0 {
+			rs, errs = runSearch(r.Context(), h.c.Providers, q, h.c.Cache, idx)
 			if len(errs) > 0 {
 				klog.Errorf("search errors: %v", errs)
 			}
@@ -80,14 +210,22 @@ func (h *Handlers) Search() http.HandlerFunc {
 		}
 
 		tmpl := template.Must(template.New("http").Funcs(fmap).Parse(string(outTmpl)))
+		kos, fos := formOptions(q)
 		ctx := templateContext{
 			Query:      q,
-			Sources:    h.c.Sources,
+			Sources:    idx.Sources(),
 			Results:    rs,
+			Weekends:   campwiz.GroupByWeekend(rs),
 			Errors:     errs,
 			SelectDate: selectDate,
 			Today:      time.Now(),
 			Version:    VERSION,
+			// Only the encoded query is trusted within the link, never the raw request
+			CalendarURL: template.URL("/search.ics?" + r.URL.Query().Encode()),
+
+			SiteKindOptions: kos,
+			FeatureOptions:  fos,
+			WeekdayOptions:  weekdayOptions(q),
 		}
 		err = tmpl.ExecuteTemplate(w, "http", ctx)
 		if err != nil {
@@ -133,6 +271,22 @@ func getFloat(url *url.URL, key string, fallback float64) float64 {
 	return fallback
 }
 
+// helper to get a list of strings from a URL, accepting repeated or comma-separated values
+func getStrs(url *url.URL, key string, fallback []string) []string {
+	var ss []string
+	for _, v := range url.Query()[key] {
+		for _, s := range strings.Split(v, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				ss = append(ss, s)
+			}
+		}
+	}
+	if len(ss) == 0 {
+		return fallback
+	}
+	return ss
+}
+
 // helper to get string from a URL
 func getStr(url *url.URL, key string, fallback string) string {
 	vals := url.Query()[key]
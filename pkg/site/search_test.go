@@ -0,0 +1,129 @@
+package site
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tstromberg/campwiz/pkg/cache"
+	"github.com/tstromberg/campwiz/pkg/campwiz"
+	"github.com/tstromberg/campwiz/pkg/search"
+)
+
+// fakeRun returns a single available campsite for every search, recording the queries it sees
+func fakeRun(seen *[]campwiz.Query) func(context.Context, []string, campwiz.Query, cache.Store, *search.Index) ([]campwiz.Result, []error) {
+	return func(ctx context.Context, providers []string, q campwiz.Query, cs cache.Store, idx *search.Index) ([]campwiz.Result, []error) {
+		*seen = append(*seen, q)
+		return []campwiz.Result{
+			{
+				Name: "Big Basin",
+				URL:  "https://example.com/big-basin",
+				Availability: []campwiz.Availability{
+					{Date: time.Date(2026, 10, 23, 0, 0, 0, 0, time.UTC), SpotCount: 2, Kind: campwiz.Tent, PricePerNight: 35, URL: "https://example.com/big-basin?date=2026-10-23"},
+				},
+			},
+		}, nil
+	}
+}
+
+func newTestHandlers(t *testing.T, seen *[]campwiz.Query) *Handlers {
+	t.Helper()
+	orig := runSearch
+	t.Cleanup(func() { runSearch = orig })
+	runSearch = fakeRun(seen)
+
+	return New(&Config{
+		BaseDirectory: "../../site",
+		Index:         search.NewLive(search.NewIndex(nil, nil)),
+		Providers:     []string{"fake"},
+	})
+}
+
+func TestSearch(t *testing.T) {
+	var seen []campwiz.Query
+	h := newTestHandlers(t, &seen)
+
+	req := httptest.NewRequest("GET", `/search?dates=2026-10-23&sort=price&max_price=40&keywords="><script>`, nil)
+	w := httptest.NewRecorder()
+	h.Search()(w, req)
+
+	resp := w.Result()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", resp.StatusCode, http.StatusOK, body)
+	}
+
+	got := string(body)
+	for _, want := range []string{
+		`href="/search.ics?dates=2026-10-23&amp;keywords=%22%3E%3Cscript%3E&amp;max_price=40&amp;sort=price"`,
+		"Big Basin",
+		"$35/night",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Search() output missing %q", want)
+		}
+	}
+
+	for _, bad := range []string{"ZgotmplZ", `"><script>`} {
+		if strings.Contains(got, bad) {
+			t.Errorf("Search() output contains %q", bad)
+		}
+	}
+
+	if len(seen) != 1 {
+		t.Fatalf("searched %d times, want 1", len(seen))
+	}
+	if seen[0].MaxPrice != 40 {
+		t.Errorf("MaxPrice = %v, want 40", seen[0].MaxPrice)
+	}
+}
+
+func TestSearchICS(t *testing.T) {
+	var seen []campwiz.Query
+	h := newTestHandlers(t, &seen)
+
+	req := httptest.NewRequest("GET", "/search.ics?dates=2026-10-23&sort=price&max_price=40&nights=1", nil)
+	w := httptest.NewRecorder()
+	h.SearchICS()(w, req)
+
+	resp := w.Result()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", resp.StatusCode, http.StatusOK, body)
+	}
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/calendar") {
+		t.Errorf("Content-Type = %q, want text/calendar", ct)
+	}
+
+	got := string(body)
+	for _, want := range []string{"BEGIN:VCALENDAR", "Big Basin", "DTSTART;VALUE=DATE:20261023", "END:VCALENDAR"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("SearchICS() output missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "ZgotmplZ") {
+		t.Errorf("SearchICS() output contains ZgotmplZ")
+	}
+
+	if len(seen) != 1 {
+		t.Fatalf("searched %d times, want 1", len(seen))
+	}
+	wantDates := []time.Time{time.Date(2026, 10, 23, 0, 0, 0, 0, time.UTC)}
+	if diff := cmp.Diff(wantDates, seen[0].Dates); diff != "" {
+		t.Errorf("query dates mismatch (-want +got):\n%s", diff)
+	}
+	if seen[0].SortBy != campwiz.SortPrice {
+		t.Errorf("SortBy = %q, want %q", seen[0].SortBy, campwiz.SortPrice)
+	}
+}
@@ -8,7 +8,7 @@ import (
 	"time"
 
 	"github.com/tstromberg/campwiz/pkg/cache"
-	"github.com/tstromberg/campwiz/pkg/campwiz"
+	"github.com/tstromberg/campwiz/pkg/search"
 	"k8s.io/klog/v2"
 )
 
@@ -19,10 +19,13 @@ const VERSION = "v1.0.0-DEV (master)"
 type Config struct {
 	BaseDirectory string
 	Cache         cache.Store
-	Sources       map[string]campwiz.Source
-	Properties    map[string]*campwiz.Property
+	Index         *search.Live
 	Providers     []string
 
+	// Reloader reloads metadata when requested by an admin presenting AdminToken
+	Reloader   *search.Reloader
+	AdminToken string
+
 	// For hardcoding a site to a particular address
 	Latitude  float64
 	Longitude float64
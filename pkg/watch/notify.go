@@ -0,0 +1,126 @@
+package watch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/smtp"
+	"strings"
+
+	"github.com/tstromberg/campwiz/pkg/render"
+)
+
+// Notifier delivers notifications
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+// subject is a one-line summary of a notification
+func subject(n Notification) string {
+	count := 0
+	for _, r := range n.Results {
+		for _, a := range r.Availability {
+			count += a.SpotCount
+		}
+	}
+	return fmt.Sprintf("campwiz: %d new sites available for %s", count, n.Name)
+}
+
+// text is a plain-text description of a notification
+func text(n Notification) string {
+	var b strings.Builder
+	b.WriteString(subject(n) + "\n")
+	for _, r := range n.Results {
+		fmt.Fprintf(&b, "\n%s (%.0fmi)\n", r.Name, r.Distance)
+		for _, a := range r.Availability {
+			fmt.Fprintf(&b, "  > %s %d: %dx%s %s\n", a.Date.Month(), a.Date.Day(), a.SpotCount, a.Kind, a.URL)
+		}
+	}
+	return b.String()
+}
+
+// TextNotifier writes notifications as plain text, such as to stdout
+type TextNotifier struct {
+	W io.Writer
+}
+
+// Notify writes a notification
+func (t *TextNotifier) Notify(_ context.Context, n Notification) error {
+	_, err := io.WriteString(t.W, text(n))
+	return err
+}
+
+// WebhookNotifier POSTs notifications as JSON to a URL
+type WebhookNotifier struct {
+	URL    string
+	Client *http.Client
+}
+
+// webhookPayload is the body sent to webhooks
+type webhookPayload struct {
+	Name    string `json:"name"`
+	Subject string `json:"subject"`
+	render.Document
+}
+
+// Notify POSTs a notification
+func (wh *WebhookNotifier) Notify(ctx context.Context, n Notification) error {
+	bs, err := json.Marshal(webhookPayload{
+		Name:     n.Name,
+		Subject:  subject(n),
+		Document: render.NewDocument(render.Context{Query: n.Query, Results: n.Results}),
+	})
+	if err != nil {
+		return fmt.Errorf("marshal: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", wh.URL, bytes.NewReader(bs))
+	if err != nil {
+		return fmt.Errorf("request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	c := wh.Client
+	if c == nil {
+		c = http.DefaultClient
+	}
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return fmt.Errorf("post: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("post %s: unexpected status %s", wh.URL, resp.Status)
+	}
+	return nil
+}
+
+// SMTPNotifier e-mails notifications
+type SMTPNotifier struct {
+	// Addr is the host:port of the mail server
+	Addr string
+	// Auth is optional
+	Auth smtp.Auth
+	From string
+	To   []string
+}
+
+// Notify sends a notification by e-mail
+func (s *SMTPNotifier) Notify(_ context.Context, n Notification) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", s.From)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(s.To, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", subject(n))
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(text(n), "\n", "\r\n"))
+
+	if err := smtp.SendMail(s.Addr, s.Auth, s.From, s.To, []byte(b.String())); err != nil {
+		return fmt.Errorf("sendmail: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package watch
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tstromberg/campwiz/pkg/campwiz"
+)
+
+func testNotification() Notification {
+	r := result("Portola", campwiz.Tent)
+	r.Availability[0].SpotCount = 3
+	r.Availability[0].URL = "https://example.com/portola"
+	return Notification{Name: "weekend", Results: []campwiz.Result{r}}
+}
+
+func TestTextNotifier(t *testing.T) {
+	var b bytes.Buffer
+	if err := (&TextNotifier{W: &b}).Notify(context.Background(), testNotification()); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	want := `campwiz: 3 new sites available for weekend
+
+Portola (0mi)
+  > February 12: 3x⛺ https://example.com/portola
+`
+	if diff := cmp.Diff(want, b.String()); diff != "" {
+		t.Errorf("Notify() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	var got webhookPayload
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("unexpected request: %s %v", r.Method, r.Header)
+		}
+		bs, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("read: %v", err)
+		}
+		if err := json.Unmarshal(bs, &got); err != nil {
+			t.Errorf("unmarshal: %v", err)
+		}
+	}))
+	defer srv.Close()
+
+	if err := (&WebhookNotifier{URL: srv.URL}).Notify(context.Background(), testNotification()); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	if got.Name != "weekend" || len(got.Results) != 1 || got.Results[0].Availability[0].SpotCount != 3 {
+		t.Errorf("unexpected payload: %+v", got)
+	}
+}
+
+func TestWebhookNotifierError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	if err := (&WebhookNotifier{URL: srv.URL}).Notify(context.Background(), testNotification()); err == nil {
+		t.Errorf("Notify() = nil, want error")
+	}
+}
+
+// fakeSMTP accepts a single message, sending its DATA to the returned channel
+func fakeSMTP(t *testing.T) (string, chan string) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	msgs := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		r := bufio.NewReader(conn)
+		reply := func(s string) { conn.Write([]byte(s + "\r\n")) }
+		reply("220 localhost fake")
+
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			cmd := strings.ToUpper(strings.TrimSpace(line))
+			switch {
+			case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+				reply("250 localhost")
+			case strings.HasPrefix(cmd, "DATA"):
+				reply("354 go ahead")
+				var data strings.Builder
+				for {
+					l, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if l == ".\r\n" {
+						break
+					}
+					data.WriteString(l)
+				}
+				msgs <- data.String()
+				reply("250 queued")
+			case strings.HasPrefix(cmd, "QUIT"):
+				reply("221 bye")
+				return
+			default:
+				reply("250 ok")
+			}
+		}
+	}()
+
+	return ln.Addr().String(), msgs
+}
+
+func TestSMTPNotifier(t *testing.T) {
+	addr, msgs := fakeSMTP(t)
+
+	s := &SMTPNotifier{Addr: addr, From: "campwiz@example.com", To: []string{"camper@example.com"}}
+	if err := s.Notify(context.Background(), testNotification()); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	got := <-msgs
+	for _, want := range []string{
+		"To: camper@example.com\r\n",
+		"Subject: campwiz: 3 new sites available for weekend\r\n",
+		"February 12: 3x⛺ https://example.com/portola\r\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("message missing %q:\n%s", want, got)
+		}
+	}
+}
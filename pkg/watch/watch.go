@@ -0,0 +1,158 @@
+// Package watch periodically searches for campsites, reporting newly available sites
+package watch
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/tstromberg/campwiz/pkg/cache"
+	"github.com/tstromberg/campwiz/pkg/campwiz"
+	"github.com/tstromberg/campwiz/pkg/search"
+	"k8s.io/klog/v2"
+)
+
+// runSearch performs a search, overridden in tests
+var runSearch = search.Run
+
+// Saved is a named query to watch
+type Saved struct {
+	Name  string
+	Query campwiz.Query
+}
+
+// Config configures a Watcher
+type Config struct {
+	Queries   []Saved
+	Providers []string
+	Cache     cache.Store
+	Index     *search.Index
+	Notifiers []Notifier
+
+	// Interval is the time between searches
+	Interval time.Duration
+	// NotifyInitial reports sites found by the first search, rather than treating them as already known
+	NotifyInitial bool
+}
+
+// Notification describes sites that have become available for a saved query
+type Notification struct {
+	Name  string
+	Query campwiz.Query
+	// Results only contain availability that is new since the previous search
+	Results []campwiz.Result
+}
+
+// Watcher repeatedly searches for saved queries
+type Watcher struct {
+	c    Config
+	seen map[string]map[string]bool
+}
+
+// New returns a new Watcher
+func New(c Config) *Watcher {
+	return &Watcher{c: c, seen: map[string]map[string]bool{}}
+}
+
+// Run checks saved queries every interval until the context is canceled
+func (w *Watcher) Run(ctx context.Context) error {
+	t := time.NewTicker(w.c.Interval)
+	defer t.Stop()
+
+	for {
+		if err := w.Check(ctx); err != nil {
+			klog.Errorf("check: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+// Check searches for each saved query once, sending notifications for new availability
+func (w *Watcher) Check(ctx context.Context) error {
+	var failed []error
+
+	for _, s := range w.c.Queries {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		rs, errs := runSearch(ctx, w.c.Providers, s.Query, w.c.Cache, w.c.Index)
+		for _, err := range errs {
+			klog.Warningf("%s search: %v", s.Name, err)
+		}
+
+		prev, known := w.seen[s.Name]
+		cur := seen(rs)
+
+		// Failing providers return partial results, so don't forget what they found previously
+		if len(errs) > 0 {
+			for k := range prev {
+				cur[k] = true
+			}
+		}
+		w.seen[s.Name] = cur
+
+		if !known && !w.c.NotifyInitial {
+			klog.Infof("%s: %d sites already available", s.Name, len(cur))
+			continue
+		}
+
+		fresh := diff(prev, rs)
+		if len(fresh) == 0 {
+			klog.Infof("%s: nothing new", s.Name)
+			continue
+		}
+
+		n := Notification{Name: s.Name, Query: s.Query, Results: fresh}
+		for _, nt := range w.c.Notifiers {
+			if err := nt.Notify(ctx, n); err != nil {
+				failed = append(failed, fmt.Errorf("%s notify: %w", s.Name, err))
+			}
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("%d notifications failed: %v", len(failed), failed)
+	}
+	return nil
+}
+
+// key uniquely identifies availability within a result
+func key(r campwiz.Result, a campwiz.Availability) string {
+	return fmt.Sprintf("%s|%s|%s|%s|%s|%s", r.ResURL, r.ResID, a.Date.Format("2006-01-02"), a.Kind, a.Name, a.Desc)
+}
+
+// seen returns the availability keys within a set of results
+func seen(rs []campwiz.Result) map[string]bool {
+	m := map[string]bool{}
+	for _, r := range rs {
+		for _, a := range r.Availability {
+			m[key(r, a)] = true
+		}
+	}
+	return m
+}
+
+// diff returns results with their availability restricted to entries not previously seen
+func diff(prev map[string]bool, rs []campwiz.Result) []campwiz.Result {
+	var fresh []campwiz.Result
+	for _, r := range rs {
+		var as []campwiz.Availability
+		for _, a := range r.Availability {
+			if !prev[key(r, a)] {
+				as = append(as, a)
+			}
+		}
+		if len(as) == 0 {
+			continue
+		}
+		r.Availability = as
+		fresh = append(fresh, r)
+	}
+	return fresh
+}
@@ -0,0 +1,128 @@
+package watch
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tstromberg/campwiz/pkg/cache"
+	"github.com/tstromberg/campwiz/pkg/campwiz"
+	"github.com/tstromberg/campwiz/pkg/search"
+)
+
+var feb12 = time.Date(2021, 2, 12, 0, 0, 0, 0, time.UTC)
+
+// fakeNotifier records notifications
+type fakeNotifier struct {
+	got []Notification
+}
+
+func (f *fakeNotifier) Notify(_ context.Context, n Notification) error {
+	f.got = append(f.got, n)
+	return nil
+}
+
+// names returns the result and availability names within notifications
+func names(ns []Notification) []string {
+	out := []string{}
+	for _, n := range ns {
+		for _, r := range n.Results {
+			for _, a := range r.Availability {
+				out = append(out, fmt.Sprintf("%s: %s %s", n.Name, r.Name, a.Kind))
+			}
+		}
+	}
+	return out
+}
+
+func result(name string, kinds ...campwiz.SiteKind) campwiz.Result {
+	r := campwiz.Result{ResURL: "https://example.com/", ResID: name, Name: name}
+	for _, k := range kinds {
+		r.Availability = append(r.Availability, campwiz.Availability{Date: feb12, Kind: k, SpotCount: 1})
+	}
+	return r
+}
+
+// script replaces runSearch with one that returns a series of canned responses
+func script(t *testing.T, steps [][]campwiz.Result, errs [][]error) {
+	orig := runSearch
+	t.Cleanup(func() { runSearch = orig })
+
+	i := 0
+	runSearch = func(context.Context, []string, campwiz.Query, cache.Store, *search.Index) ([]campwiz.Result, []error) {
+		if i >= len(steps) {
+			t.Fatalf("unexpected search #%d", i)
+		}
+		rs, es := steps[i], errs[i]
+		i++
+		return rs, es
+	}
+}
+
+func TestCheck(t *testing.T) {
+	script(t, [][]campwiz.Result{
+		{result("Portola", campwiz.Tent)},
+		{result("Portola", campwiz.Tent, campwiz.RV), result("Butano", campwiz.Walk)},
+		{result("Portola", campwiz.RV)},
+		{result("Portola", campwiz.Tent, campwiz.RV)},
+	}, [][]error{nil, nil, nil, nil})
+
+	fn := &fakeNotifier{}
+	w := New(Config{Queries: []Saved{{Name: "weekend"}}, Notifiers: []Notifier{fn}})
+
+	var tests = []struct {
+		name string
+		want []string
+	}{
+		{"initial", []string{}},
+		{"new sites", []string{"weekend: Portola 🚚", "weekend: Butano 🥾"}},
+		{"gone", []string{}},
+		{"reopened", []string{"weekend: Portola ⛺"}},
+	}
+
+	for _, tt := range tests {
+		fn.got = nil
+		if err := w.Check(context.Background()); err != nil {
+			t.Fatalf("%s: Check: %v", tt.name, err)
+		}
+		if diff := cmp.Diff(tt.want, names(fn.got)); diff != "" {
+			t.Errorf("%s: notifications mismatch (-want +got):\n%s", tt.name, diff)
+		}
+	}
+}
+
+func TestCheckNotifyInitial(t *testing.T) {
+	script(t, [][]campwiz.Result{{result("Portola", campwiz.Tent)}}, [][]error{nil})
+
+	fn := &fakeNotifier{}
+	w := New(Config{Queries: []Saved{{Name: "now"}}, Notifiers: []Notifier{fn}, NotifyInitial: true})
+	if err := w.Check(context.Background()); err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+
+	if diff := cmp.Diff([]string{"now: Portola ⛺"}, names(fn.got)); diff != "" {
+		t.Errorf("notifications mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestCheckProviderError(t *testing.T) {
+	script(t, [][]campwiz.Result{
+		{result("Portola", campwiz.Tent), result("Butano", campwiz.Walk)},
+		{result("Portola", campwiz.Tent)},
+		{result("Portola", campwiz.Tent), result("Butano", campwiz.Walk)},
+	}, [][]error{nil, {fmt.Errorf("scc list: session expired")}, nil})
+
+	fn := &fakeNotifier{}
+	w := New(Config{Queries: []Saved{{Name: "weekend"}}, Notifiers: []Notifier{fn}})
+	for i := 0; i < 3; i++ {
+		if err := w.Check(context.Background()); err != nil {
+			t.Fatalf("Check: %v", err)
+		}
+	}
+
+	if len(fn.got) > 0 {
+		t.Errorf("got notifications %v, want none after a provider recovers", names(fn.got))
+	}
+}